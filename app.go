@@ -0,0 +1,346 @@
+package cfop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// App is a CLI. Its zero value isn't valid, since Name and Root must
+// be provided.
+// An App isn't changed when run, so it can be run any number of times,
+// including concurrently, as long as its fields and parsers aren't
+// changed meanwhile.
+type App struct {
+	// Name is the root cmd's name.
+	Name        string
+	Description string
+	// Version is the version of the CLI. If it's provided, the root cmd
+	// accepts the --version and -V flags and the version subcmd, which
+	// print it. The version subcmd also accepts the --json flag.
+	//
+	// The built-in subcmds, version and completion, are listed in the
+	// root cmd's help message. If the root is a Cmd that accepts
+	// arguments, there are no built-in subcmds, so that its first
+	// argument can be any value.
+	Version string
+	// VersionBuildInfo enriches the version printed with the module
+	// path, VCS revision and Go version read from the binary's build
+	// info, if it's available. The VCS revision is only available in
+	// binaries built with Go 1.18 or later.
+	VersionBuildInfo bool
+	// Root is the parser of the terms after the root cmd's name.
+	Root Parser
+	// Middlewares wrap the function of any cmd reached. They're the
+	// outermost middlewares, wrapping any registered with Use.
+	Middlewares []Middleware
+	// IO are the streams used for every output produced, such as help
+	// messages, completion scripts and errors. They're also available to
+	// the cmd reached via its CmdTermsSet.
+	IO IOStreams
+	// AlphabeticalOrder lists options, flags and subcmds sorted by name
+	// in help messages and completion, instead of in the order they were
+	// declared.
+	AlphabeticalOrder bool
+	// HelpWidth is the number of columns help messages are wrapped at.
+	// If it's <= 0, it's read from the COLUMNS environment variable or,
+	// if it isn't set, from the terminal the output is written to. If
+	// neither is available, 67 is used.
+	HelpWidth int
+	// HelpMaxWidth limits the number of columns detected when HelpWidth
+	// <= 0, so that help messages stay readable in wide terminals. If
+	// it's <= 0, there's no limit.
+	HelpMaxWidth int
+	// Color controls whether the output, such as help messages, is
+	// styled. If it's empty, ColorAuto is used.
+	Color ColorMode
+	// ColorOption enables the --color option, which overrides Color with
+	// auto, always or never, e.g. --color=never. It's accepted by every
+	// cmd and subcmds set before --, except in a cmd with an option or
+	// flag named color, and it's listed in every help message.
+	ColorOption bool
+	// HelpTemplate is the text/template used to render help messages
+	// from a HelpData. It can use the functions described in
+	// DefaultHelpTemplate. If it's empty, DefaultHelpTemplate is used.
+	// It's parsed by Parse, which returns an ErrInvalidHelpTemplate if
+	// it isn't valid, even if no help message is asked for.
+	HelpTemplate string
+	// DisableCompletion disables the built-in completion subcmd and the
+	// __introspect__ term used by the completion scripts.
+	DisableCompletion bool
+	// ResponseFiles enables replacing every term of the form @path with
+	// the terms read from the file at path before parsing them. Terms in
+	// a response file are separated by whitespace and can be quoted. A
+	// response file can reference other response files up to
+	// ResponseFilesMaxDepth levels, with relative paths being relative to
+	// its directory. If ResponseFilesMaxDepth <= 0,
+	// DefaultResponseFilesMaxDepth is used.
+	ResponseFiles         bool
+	ResponseFilesMaxDepth int
+	// Signals enables cancelling the context passed to the CtxFn of the
+	// cmd reached when SIGINT or SIGTERM is received, so that it can
+	// shut down gracefully. If another one of them is received or the cmd
+	// doesn't return within SignalsGracePeriod, the program exits
+	// immediately with ExitCodeInterrupted for SIGINT or
+	// ExitCodeTerminated for SIGTERM. If SignalsGracePeriod <= 0,
+	// it waits indefinitely.
+	Signals            bool
+	SignalsGracePeriod time.Duration
+}
+
+// Run parses args, which are the terms after the root cmd's name, e.g.
+// os.Args[1:], and runs the cmd reached.
+func (a *App) Run(args []string) error {
+	return a.RunContext(context.Background(), args)
+}
+
+// RunContext is like Run, but ctx is passed to the CtxFn of the cmd
+// reached, so that it can be cancelled. Any error returned by the CtxFn
+// is returned by RunContext.
+func (a *App) RunContext(ctx context.Context, args []string) error {
+	r, err := a.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if a.Signals {
+		var stop func()
+
+		ctx, stop = notifySignals(ctx, a.SignalsGracePeriod)
+		defer stop()
+	}
+
+	return r.Run(ctx)
+}
+
+// Parse parses args like Run, but nothing is run, not even the help
+// message or the version being printed. Instead, what args resolve to is
+// returned, so that the caller decides whether to run it.
+func (a *App) Parse(args []string) (*ParseResult, error) {
+	if a.Name == "" {
+		return nil, ErrMissingRootCmdName
+	}
+
+	if a.Root == nil {
+		return nil, ErrMissingRootParser
+	}
+
+	if a.Color != "" && !isValidColorMode(a.Color) {
+		return nil, ErrInvalidColorMode{Mode: a.Color}
+	}
+
+	helpTemplate := defaultHelpTemplate
+
+	if a.HelpTemplate != "" {
+		var err error
+
+		helpTemplate, err = parseHelpTemplate(a.HelpTemplate)
+		if err != nil {
+			return nil, ErrInvalidHelpTemplate{Err: err}
+		}
+	}
+
+	builtins := a.builtinSubcmds()
+
+	r := &ParseResult{}
+	pp := parentParser{
+		cmds: []string{a.Name},
+		parser: &rootCmd{
+			name:        a.Name,
+			description: a.Description,
+		},
+		result:       r,
+		alphabetical: a.AlphabeticalOrder,
+		helpTemplate: helpTemplate,
+		helpWidth:    a.HelpWidth,
+		helpMaxWidth: a.HelpMaxWidth,
+		colorOption:  a.ColorOption,
+		colorMode:    a.Color,
+		streams:      a.IO.withDefaults(),
+		hooks:        []*hooks{{middlewares: a.Middlewares}},
+		builtins:     builtins,
+	}
+
+	// Introspection
+	if !a.DisableCompletion && len(args) > 0 && args[0] == "__introspect__" {
+		return r, pp.resolve(ParseResult{Path: []string{a.Name}}, func(context.Context) error {
+			fmt.Fprintln(pp.streams.Out, strings.Join(
+				introspectParser(args[1:], a.Root, a.AlphabeticalOrder),
+				" ",
+			))
+
+			return nil
+		})
+	}
+
+	// positions are the positions in the terms provided of the terms
+	// parsed, which are different if response files are expanded.
+	var positions []int
+
+	if a.ResponseFiles {
+		var err error
+
+		args, positions, err = expandResponseFiles(args, a.ResponseFilesMaxDepth)
+		if err != nil {
+			return nil, newErrParse(pp, "", nil, -1, err)
+		}
+	}
+
+	if len(args) > 0 && a.Version != "" && isVersionFlag(args[0]) && !hasOptionOrFlag(a.Root, args[0]) {
+		return r, pp.resolve(ParseResult{Path: []string{a.Name}}, func(context.Context) error {
+			return printVersion(pp.streams.Out, a, false)
+		})
+	}
+
+	p := a.Root
+
+	// A subcmds set looks up the built-in subcmds itself, while a cmd
+	// only has them replacing its first term.
+	if _, ok := p.(*Cmd); ok && len(args) > 0 && pp.builtins != nil {
+		if _, ok := pp.builtins.items[args[0]]; ok {
+			p = pp.builtins
+		}
+	}
+
+	if err := p.Parse(pp, args); err != nil {
+		return nil, withOriginalIndexes(err, positions)
+	}
+
+	return r, nil
+}
+
+// builtinSubcmds returns the built-in subcmds enabled in a. Since they
+// take the place of the first term, there are none if a.Root is a cmd
+// that accepts arguments, in which case nil is returned.
+func (a *App) builtinSubcmds() *SubcmdsSet {
+	if c, ok := a.Root.(*Cmd); ok && (len(c.argsByPos) > 0 || c.strictOrder) {
+		return nil
+	}
+
+	set := NewSubcmdsSet()
+
+	if !a.DisableCompletion {
+		set.Add("completion", "prints completion for a shell", newCompletionParser(a.Name))
+	}
+
+	if a.Version != "" {
+		set.Add("version", "prints the version", newVersionParser(a))
+	}
+
+	if len(set.items) == 0 {
+		return nil
+	}
+
+	return set
+}
+
+// Exec is like RunContext, but any error returned is handled by
+// HandleError and the exit code returned by it is returned.
+func (a *App) Exec(ctx context.Context, args []string) int {
+	return a.HandleError(a.RunContext(ctx, args))
+}
+
+// HandleError prints err to the error stream and returns the exit code
+// for it, as returned by ExitCode. For parse errors, the usage line of
+// the cmd being parsed and a hint on how to get its help message are also
+// printed. If err is nil or an ErrExit without an error, nothing is
+// printed.
+func (a *App) HandleError(err error) int {
+	printError(a.IO.withDefaults().Err, a.Name, err)
+
+	return ExitCode(err)
+}
+
+// Main calls Exec with os.Args[1:] and exits with the code returned.
+func (a *App) Main() {
+	os.Exit(a.Exec(context.Background(), os.Args[1:]))
+}
+
+// hasOptionOrFlag returns whether p is a cmd with an option or a flag
+// referenced by str, e.g. --version.
+func hasOptionOrFlag(p Parser, str string) bool {
+	c, ok := p.(*Cmd)
+	if !ok {
+		return false
+	}
+
+	name, isAlias := extractOptionName(str)
+	opt, f, _ := c.findOptionOrFlag(name, isAlias)
+
+	return opt != nil || f != nil
+}
+
+// ValidateSeeAlso returns an ErrUnknownSeeAlsoPath if an entry of the
+// SeeAlso of a cmd reachable from a.Root isn't the path of a cmd or
+// subcmds set of a, including the built-in subcmds. Since it walks every
+// cmd, it isn't called when a is run, but it can be called from a's
+// tests.
+func (a *App) ValidateSeeAlso() error {
+	if a.Name == "" {
+		return ErrMissingRootCmdName
+	}
+
+	if a.Root == nil {
+		return ErrMissingRootParser
+	}
+
+	return validateSeeAlso(a.Name, a.Root, a.builtinSubcmds())
+}
+
+// validateSeeAlso returns an error if an entry of the SeeAlso of a cmd
+// reachable from root isn't the path of a cmd or subcmds set of the tree
+// whose root cmd is name, including the built-in subcmds.
+func validateSeeAlso(name string, root Parser, builtins *SubcmdsSet) error {
+	type cmdPath struct {
+		cmd  *Cmd
+		path string
+	}
+
+	paths := make(map[string]bool)
+	inPath := make(map[*SubcmdsSet]bool)
+
+	var cmds []cmdPath
+	var walk func(p Parser, path string)
+
+	walk = func(p Parser, path string) {
+		paths[path] = true
+
+		switch p := p.(type) {
+		case *Cmd:
+			cmds = append(cmds, cmdPath{p, path})
+		case *SubcmdsSet:
+			// A subcmds set can't be walked again inside itself.
+			if inPath[p] {
+				return
+			}
+
+			inPath[p] = true
+
+			for _, item := range p.orderedItems(false) {
+				walk(item.Parser, path+" "+item.Name)
+			}
+
+			inPath[p] = false
+		}
+	}
+
+	walk(root, name)
+
+	if builtins != nil {
+		for _, item := range builtins.orderedItems(false) {
+			paths[name+" "+item.Name] = true
+		}
+	}
+
+	for _, c := range cmds {
+		for _, seeAlso := range c.cmd.seeAlso {
+			if !paths[strings.Join(strings.Fields(seeAlso), " ")] {
+				return ErrUnknownSeeAlsoPath{Cmd: c.path, Path: seeAlso}
+			}
+		}
+	}
+
+	return nil
+}
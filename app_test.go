@@ -0,0 +1,343 @@
+package cfop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAppExec(t *testing.T) {
+	set := NewSubcmdsSet(
+		Subcmd{
+			Name: "foo",
+			Parser: NewCmd(CmdConfig{
+				CtxFn: func(ctx context.Context, cts *CmdTermsSet) error {
+					switch cts.GetArgString("name") {
+					case "fail":
+						return errors.New("failed")
+					case "exit":
+						return ErrExit{Code: 5, Err: errors.New("exited")}
+					}
+
+					return nil
+				},
+				Args: []CmdArg{
+					{Name: "name", T: TermString},
+				},
+				Flags: []CmdFlag{
+					{Name: "line"},
+				},
+			}),
+		},
+	)
+
+	tests := []struct {
+		strs []string
+		code int
+		out  string
+	}{
+		{
+			[]string{"testing", "foo", "bar"},
+			0,
+			"",
+		},
+		{
+			[]string{"testing", "foo", "fail"},
+			ExitCodeError,
+			"testing: failed\n",
+		},
+		{
+			[]string{"testing", "foo", "exit"},
+			5,
+			"testing: exited\n",
+		},
+		{
+			[]string{"testing", "foo", "bar", "--what"},
+			ExitCodeParseError,
+			"testing: unexpected --what option/flag\n" +
+				"Usage: testing foo <name> [FLAGS]\n" +
+				"Run 'testing foo --help' for more information.\n",
+		},
+		{
+			[]string{"testing", "foo"},
+			ExitCodeParseError,
+			"testing: missing argument(s): #1 <name> (string)\n" +
+				"Usage: testing foo <name> [FLAGS]\n" +
+				"Run 'testing foo --help' for more information.\n",
+		},
+		{
+			[]string{"testing", "bar"},
+			ExitCodeParseError,
+			"testing: unknown subcmd: bar\n" +
+				"Usage: testing SUBCMD\n" +
+				"Run 'testing --help' for more information.\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			app := &App{
+				Name: "testing",
+				Root: set,
+				IO:   IOStreams{Err: &buf},
+			}
+
+			code := app.Exec(context.Background(), test.strs[1:])
+
+			if code != test.code {
+				t.Errorf("got %v, want %v", code, test.code)
+			}
+
+			if buf.String() != test.out {
+				t.Errorf("got %q, want %q", buf.String(), test.out)
+			}
+		})
+	}
+}
+
+func TestAppRun(t *testing.T) {
+	var mu sync.Mutex
+	names := make(map[string]bool)
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {
+			mu.Lock()
+			names[cts.GetArgString("name")] = true
+			mu.Unlock()
+		},
+		Args: []CmdArg{
+			{Name: "name", T: TermString},
+		},
+	})
+
+	t.Run("cmd root without terms", func(t *testing.T) {
+		app := &App{Name: "testing", Root: cmd}
+
+		var errMissingArgs ErrMissingArguments
+		if err := app.Run(nil); !errors.As(err, &errMissingArgs) {
+			t.Errorf("got %v, want ErrMissingArguments", err)
+		}
+	})
+
+	t.Run("completion with cmd root", func(t *testing.T) {
+		var out bytes.Buffer
+
+		root := NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}})
+		app := &App{Name: "testing", Root: root, IO: IOStreams{Out: &out}}
+
+		if err := app.Run([]string{"completion", "bash"}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		if !strings.HasPrefix(out.String(), "_testing()") {
+			t.Errorf("got %q, want the completion script", out.String())
+		}
+	})
+
+	t.Run("completion as argument of cmd root", func(t *testing.T) {
+		app := &App{Name: "testing", Root: cmd, Version: "1.2.3"}
+
+		for _, name := range []string{"completion", "version"} {
+			if err := app.Run([]string{name}); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !names[name] {
+				t.Errorf("%v wasn't taken as the argument", name)
+			}
+		}
+	})
+
+	t.Run("doesn't change the root", func(t *testing.T) {
+		set := NewSubcmdsSet(Subcmd{Name: "foo", Parser: cmd})
+		app := &App{Name: "testing", Root: set, IO: IOStreams{Out: &bytes.Buffer{}}}
+
+		if err := app.Run([]string{"completion", "bash"}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		if len(set.items) != 1 {
+			t.Errorf("got %v subcmds, want 1", len(set.items))
+		}
+	})
+
+	t.Run("user's completion subcmd", func(t *testing.T) {
+		set := NewSubcmdsSet(
+			Subcmd{Name: "completion", Parser: cmd},
+		)
+		app := &App{Name: "testing", Root: set}
+
+		if err := app.Run([]string{"completion", "custom"}); err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+
+		if !names["custom"] {
+			t.Error("user's completion subcmd wasn't called")
+		}
+	})
+
+	t.Run("disabled completion", func(t *testing.T) {
+		app := &App{Name: "testing", Root: NewSubcmdsSet(Subcmd{Name: "foo", Parser: cmd}), DisableCompletion: true}
+
+		if err := app.Run([]string{"completion", "bash"}); !errors.Is(err, ErrUnknownSubcmd{SubcmdName: "completion"}) {
+			t.Errorf("got %v, want ErrUnknownSubcmd", err)
+		}
+	})
+
+	t.Run("concurrent runs", func(t *testing.T) {
+		app := &App{
+			Name: "testing",
+			Root: NewSubcmdsSet(
+				Subcmd{
+					Name:   "foo",
+					Parser: NewSubcmdsSet(Subcmd{Name: "bar", Parser: cmd}),
+				},
+			),
+		}
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				if err := app.Run([]string{"foo", "bar", "name" + strconv.Itoa(i)}); err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		for i := 0; i < 50; i++ {
+			if !names["name"+strconv.Itoa(i)] {
+				t.Errorf("name%v wasn't parsed", i)
+			}
+		}
+	})
+
+	t.Run("missing name and root", func(t *testing.T) {
+		if err := (&App{Root: cmd}).Run(nil); err != ErrMissingRootCmdName {
+			t.Errorf("got %v, want %v", err, ErrMissingRootCmdName)
+		}
+
+		if err := (&App{Name: "testing"}).Run(nil); err != ErrMissingRootParser {
+			t.Errorf("got %v, want %v", err, ErrMissingRootParser)
+		}
+	})
+}
+
+func TestAppParse(t *testing.T) {
+	called := false
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {
+			called = true
+		},
+		Args: []CmdArg{
+			{Name: "name", T: TermString},
+		},
+	})
+	app := &App{
+		Name:    "testing",
+		Version: "1.0.0",
+		Root:    NewSubcmdsSet(Subcmd{Name: "foo", Parser: cmd}),
+	}
+
+	tests := []struct {
+		strs    []string
+		path    []string
+		cmd     *Cmd
+		help    bool
+		argName string
+		out     string
+	}{
+		{
+			strs:    []string{"testing", "foo", "bar"},
+			path:    []string{"testing", "foo"},
+			cmd:     cmd,
+			argName: "bar",
+		},
+		{
+			strs: []string{"testing", "foo", "--help"},
+			path: []string{"testing", "foo"},
+			cmd:  cmd,
+			help: true,
+			out:  "Usage: testing foo <name>",
+		},
+		{
+			strs: []string{"testing", "--help"},
+			path: []string{"testing"},
+			help: true,
+			out:  "Usage: testing SUBCMD",
+		},
+		{
+			strs: []string{"testing", "--version"},
+			path: []string{"testing"},
+			out:  "testing 1.0.0",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			called = false
+			a := *app
+			a.IO = IOStreams{Out: &out}
+
+			r, err := a.Parse(test.strs[1:])
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if called || out.Len() != 0 {
+				t.Fatal("got something run, want nothing run")
+			}
+
+			if !reflect.DeepEqual(r.Path, test.path) {
+				t.Errorf("got %v, want %v", r.Path, test.path)
+			}
+
+			if r.Cmd != test.cmd {
+				t.Errorf("got %v, want %v", r.Cmd, test.cmd)
+			}
+
+			if r.Help != test.help {
+				t.Errorf("got %v, want %v", r.Help, test.help)
+			}
+
+			if test.argName != "" && r.Terms.GetArgString("name") != test.argName {
+				t.Errorf("got %v, want %v", r.Terms.GetArgString("name"), test.argName)
+			}
+
+			if err := r.Run(context.Background()); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if test.out == "" && !called {
+				t.Error("got cmd not called, want it called")
+			}
+
+			if !strings.Contains(out.String(), test.out) {
+				t.Errorf("got %q, want it to contain %q", out.String(), test.out)
+			}
+		})
+	}
+
+	t.Run("parse error", func(t *testing.T) {
+		var errParse ErrParse
+
+		if _, err := app.Parse([]string{"bar"}); !errors.As(err, &errParse) {
+			t.Errorf("got %v, want ErrParse", err)
+		}
+	})
+}
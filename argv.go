@@ -0,0 +1,245 @@
+package cfop
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var attachedOptionValueRegExp = regexp.MustCompile("^\\S+$")
+var shellSafeRegExp = regexp.MustCompile("^[A-Za-z0-9_@%+=:,./-]+$")
+
+// TermValues are the values of the terms of a cmd, used to build its
+// terms with Cmd.Argv.
+type TermValues struct {
+	// Options are the values of the options by name or alias. The value
+	// of a map option is a map[string]interface{}.
+	Options map[string]interface{}
+	// Flags are the values of the flags by name or alias.
+	Flags map[string]bool
+	// Args are the values of the arguments by name.
+	Args map[string]interface{}
+	// Passthrough are the terms after every argument, which are only
+	// accepted by a cmd with strict order.
+	Passthrough []string
+}
+
+// Argv returns the terms that, when parsed, result in ct, starting with
+// the terms of its path. The terms are canonical, i.e. options and flags
+// are sorted by name and come before the arguments.
+func (ct *CmdTermsSet) Argv() []string {
+	// The values of a terms set always have the type of their terms, so
+	// there's no error.
+	argv, _ := ct.cmd.argv(ct.path, TermValues{
+		Options:     ct.optionsValues,
+		Flags:       ct.flagsValues,
+		Args:        ct.argsValues,
+		Passthrough: ct.passthrough,
+	})
+
+	return argv
+}
+
+// Argv returns the canonical terms of c with values, starting with the
+// terms of path, as returned by CmdTermsSet.Argv. If the terms don't
+// parse, e.g. because a value has a Go type different than the one of
+// its option or argument, the parse error is returned. The Go type of a
+// value must be string, int or float64 for TermString, TermInt or
+// TermFloat, respectively, or a map[string]interface{} of them for a map
+// option.
+func (c *Cmd) Argv(path []string, values TermValues) ([]string, error) {
+	resolved := TermValues{
+		Options:     make(map[string]interface{}, len(values.Options)),
+		Flags:       make(map[string]bool, len(values.Flags)),
+		Args:        values.Args,
+		Passthrough: values.Passthrough,
+	}
+
+	for name, value := range values.Options {
+		if opt := c.getOption(name); opt != nil {
+			name = opt.Name
+		}
+
+		resolved.Options[name] = value
+	}
+
+	for name, value := range values.Flags {
+		if f := c.getFlag(name); f != nil {
+			name = f.Name
+		}
+
+		resolved.Flags[name] = value
+	}
+
+	pp := parentParser{cmds: path, parser: c, result: &ParseResult{}}
+
+	for name := range values.Args {
+		if c.getArgByName(name) == nil {
+			return nil, newErrParse(pp, c.usage(pp), nil, -1, ErrUnexpectedArgument{Argument: name})
+		}
+	}
+
+	argv, err := c.argv(path, resolved)
+	if err != nil {
+		return nil, newErrParse(pp, c.usage(pp), nil, -1, err)
+	}
+
+	if _, err := c.parse(pp, argv[len(path):]); err != nil {
+		return nil, err
+	}
+
+	return argv, nil
+}
+
+// argv returns the canonical terms of c with values, whose options and
+// flags are referenced by name. If the Go type of a value isn't the one
+// of its term, an error is returned.
+func (c *Cmd) argv(path []string, values TermValues) ([]string, error) {
+	argv := append([]string(nil), path...)
+
+	for _, name := range sortedKeys(values.Options) {
+		opt := c.getOption(name)
+		if opt == nil {
+			return nil, ErrUnexpectedOption{OptionName: name}
+		}
+
+		errType := ErrOptionExpectsDifferentValueType{
+			OptionName:   name,
+			ExpectedType: opt.T,
+		}
+
+		if !opt.Map {
+			value, ok := formatTermValue(opt.T, values.Options[name])
+			if !ok {
+				return nil, errType
+			}
+
+			argv = appendOption(argv, name, value)
+
+			continue
+		}
+
+		m, ok := values.Options[name].(map[string]interface{})
+		if !ok {
+			return nil, errType
+		}
+
+		for _, key := range sortedKeys(m) {
+			value, ok := formatTermValue(opt.T, m[key])
+			if !ok {
+				return nil, errType
+			}
+
+			argv = appendOption(argv, name, key+"="+value)
+		}
+	}
+
+	flagNames := make([]string, 0, len(values.Flags))
+
+	for name, value := range values.Flags {
+		if value {
+			flagNames = append(flagNames, name)
+		}
+	}
+
+	sort.Strings(flagNames)
+
+	for _, name := range flagNames {
+		argv = append(argv, "--"+name)
+	}
+
+	args := make([]string, 0, len(values.Args)+len(values.Passthrough))
+
+	for pos, arg := range c.argsByPos {
+		value, ok := values.Args[arg.Name]
+		if !ok {
+			continue
+		}
+
+		str, ok := formatTermValue(arg.T, value)
+		if !ok {
+			return nil, ErrArgumentExpectsDifferentValueType{
+				ArgumentPos:  pos,
+				ArgumentName: arg.Name,
+				ExpectedType: arg.T,
+				Value:        fmt.Sprint(value),
+			}
+		}
+
+		args = append(args, str)
+	}
+
+	args = append(args, values.Passthrough...)
+
+	// -- is only added when needed, so that the terms stay as short as
+	// possible.
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			argv = append(argv, "--")
+
+			break
+		}
+	}
+
+	return append(argv, args...), nil
+}
+
+// appendOption appends the terms of an option named name with value to
+// argv. The value is attached to the option, e.g. --name=value, unless
+// it's empty or contains whitespace, which can't be attached.
+func appendOption(argv []string, name, value string) []string {
+	if attachedOptionValueRegExp.MatchString(value) {
+		return append(argv, "--"+name+"="+value)
+	}
+
+	return append(argv, "--"+name, value)
+}
+
+// formatTermValue formats value, which is the value of a term of type t,
+// so that it parses back to value. If the Go type of value isn't the one
+// of t, false is returned.
+func formatTermValue(t TermType, value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, t == TermString
+	case int:
+		return strconv.Itoa(v), t == TermInt
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), t == TermFloat
+	default:
+		return "", false
+	}
+}
+
+// sortedKeys returns the keys of m sorted.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ShellQuote joins argv into a string that a POSIX shell splits back into
+// argv. Terms with characters special to the shell are single-quoted.
+func ShellQuote(argv []string) string {
+	quoted := make([]string, 0, len(argv))
+
+	for _, term := range argv {
+		if shellSafeRegExp.MatchString(term) {
+			quoted = append(quoted, term)
+
+			continue
+		}
+
+		quoted = append(quoted, "'"+strings.ReplaceAll(term, "'", `'\''`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
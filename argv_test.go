@@ -0,0 +1,337 @@
+package cfop
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestCmdTermsSetArgv(t *testing.T) {
+	tests := []struct {
+		config CmdConfig
+		strs   []string
+		argv   []string
+	}{
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt},
+					{Name: "salary", T: TermFloat},
+				},
+				Flags: []CmdFlag{
+					{Name: "line", Alias: "l"},
+					{Name: "all"},
+				},
+				Args: []CmdArg{
+					{Name: "first", T: TermString},
+				},
+			},
+			strs: []string{"foo", "-n", "John Doe", "-l", "-y", "-5", "--salary=2.50", "--all"},
+			argv: []string{"app", "sub", "--name", "John Doe", "--salary=2.5", "--year=-5", "--all", "--line", "foo"},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "name", T: TermString},
+					{Name: "label", Alias: "l", T: TermString, Map: true},
+				},
+				Args: []CmdArg{
+					{Name: "first", T: TermString},
+				},
+			},
+			strs: []string{"--name", "", "-l", "tier=web", "-l", "env=a b", "--", "-foo"},
+			argv: []string{"app", "sub", "--label", "env=a b", "--label=tier=web", "--name", "", "--", "-foo"},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "tty", Alias: "t"},
+				},
+				Args: []CmdArg{
+					{Name: "cmd", T: TermString},
+				},
+				StrictOrder: true,
+			},
+			strs: []string{"-t", "ls", "-l", "--help"},
+			argv: []string{"app", "sub", "--tty", "--", "ls", "-l", "--help"},
+		},
+		{
+			config: CmdConfig{
+				Args: []CmdArg{
+					{Name: "n", T: TermInt},
+				},
+			},
+			strs: []string{"-3"},
+			argv: []string{"app", "sub", "--", "-3"},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			test.config.Fn = func(cts *CmdTermsSet) {}
+			c := NewCmd(test.config)
+
+			tSet, err := c.parse(parentParser{cmds: []string{"app", "sub"}}, test.strs)
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			argv := tSet.Argv()
+
+			if !reflect.DeepEqual(argv, test.argv) {
+				t.Errorf("got %q, want %q", argv, test.argv)
+			}
+		})
+	}
+}
+
+func TestCmdArgv(t *testing.T) {
+	c := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "year", Alias: "y", T: TermInt, Required: true},
+			{Name: "label", T: TermString, Map: true},
+		},
+		Flags: []CmdFlag{
+			{Name: "line", Alias: "l"},
+		},
+		Args: []CmdArg{
+			{Name: "first", T: TermString},
+		},
+	})
+
+	tests := []struct {
+		values TermValues
+		argv   []string
+		err    error
+	}{
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"y": 1990},
+				Flags:   map[string]bool{"l": true},
+				Args:    map[string]interface{}{"first": "foo"},
+			},
+			argv: []string{"app", "--year=1990", "--line", "foo"},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"year": "abc"},
+				Args:    map[string]interface{}{"first": "foo"},
+			},
+			err: ErrOptionExpectsDifferentValueType{OptionName: "year", ExpectedType: TermInt},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"year": 1990, "name": "John"},
+				Args:    map[string]interface{}{"first": "foo"},
+			},
+			err: ErrUnexpectedOption{OptionName: "name"},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"year": 1990},
+				Args:    map[string]interface{}{"first": "foo", "second": "bar"},
+			},
+			err: ErrUnexpectedArgument{Argument: "second"},
+		},
+		{
+			values: TermValues{
+				Args: map[string]interface{}{"first": "foo"},
+			},
+			err: ErrRequiredOptionNotProvided{OptionName: "year"},
+		},
+		{
+			values: TermValues{
+				Options:     map[string]interface{}{"year": 1990},
+				Args:        map[string]interface{}{"first": "foo"},
+				Passthrough: []string{"bar"},
+			},
+			err: ErrUnexpectedArgument{Argument: "bar"},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{
+					"year":  1990,
+					"label": map[string]interface{}{"env": "prod"},
+				},
+				Args: map[string]interface{}{"first": "foo"},
+			},
+			argv: []string{"app", "--label=env=prod", "--year=1990", "foo"},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"year": 1990.0},
+				Args:    map[string]interface{}{"first": "foo"},
+			},
+			err: ErrOptionExpectsDifferentValueType{OptionName: "year", ExpectedType: TermInt},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{
+					"year":  1990,
+					"label": map[string]string{"env": "prod"},
+				},
+				Args: map[string]interface{}{"first": "foo"},
+			},
+			err: ErrOptionExpectsDifferentValueType{OptionName: "label", ExpectedType: TermString},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{
+					"year":  1990,
+					"label": map[string]interface{}{"env": 1},
+				},
+				Args: map[string]interface{}{"first": "foo"},
+			},
+			err: ErrOptionExpectsDifferentValueType{OptionName: "label", ExpectedType: TermString},
+		},
+		{
+			values: TermValues{
+				Options: map[string]interface{}{"year": 1990},
+				Args:    map[string]interface{}{"first": 5},
+			},
+			err: ErrArgumentExpectsDifferentValueType{
+				ArgumentPos:  0,
+				ArgumentName: "first",
+				ExpectedType: TermString,
+				Value:        "5",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			argv, err := c.Argv([]string{"app"}, test.values)
+
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("got %v, want %v", err, test.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !reflect.DeepEqual(argv, test.argv) {
+				t.Errorf("got %q, want %q", argv, test.argv)
+			}
+		})
+	}
+}
+
+func TestArgvRoundTrip(t *testing.T) {
+	for _, strictOrder := range []bool{false, true} {
+		c := NewCmd(CmdConfig{
+			Fn: func(cts *CmdTermsSet) {},
+			Options: []CmdOption{
+				{Name: "name", Alias: "n", T: TermString},
+				{Name: "year", T: TermInt},
+				{Name: "salary", T: TermFloat},
+				{Name: "label", T: TermString, Map: true},
+			},
+			Flags: []CmdFlag{
+				{Name: "verbose", Alias: "v"},
+			},
+			Args: []CmdArg{
+				{Name: "first", T: TermString},
+				{Name: "second", T: TermFloat},
+			},
+			StrictOrder: strictOrder,
+		})
+
+		roundTrip := func(name string, year int, salary float64, labels map[string]string, verbose bool, first string, second float64, passthrough []string) bool {
+			labelsValue := make(map[string]interface{}, len(labels))
+
+			for k, v := range labels {
+				if k != "" && !strings.Contains(k, "=") {
+					labelsValue[k] = v
+				}
+			}
+
+			if !strictOrder {
+				passthrough = nil
+			}
+
+			// An empty map is the same as a map option not provided.
+			if len(labelsValue) == 0 {
+				labelsValue = nil
+			}
+
+			values := TermValues{
+				Options: map[string]interface{}{
+					"name":   name,
+					"year":   year,
+					"salary": salary,
+					"label":  labelsValue,
+				},
+				Flags:       map[string]bool{"verbose": verbose},
+				Args:        map[string]interface{}{"first": first, "second": second},
+				Passthrough: passthrough,
+			}
+
+			argv, err := c.Argv([]string{"app"}, values)
+			if err != nil {
+				t.Logf("got %v, want nil", err)
+
+				return false
+			}
+
+			tSet, err := c.parse(parentParser{cmds: []string{"app"}}, argv[1:])
+			if err != nil {
+				t.Logf("got %v, want nil", err)
+
+				return false
+			}
+
+			return tSet.GetOptString("name") == name &&
+				tSet.GetOptInt("year") == year &&
+				tSet.GetOptFloat("salary") == salary &&
+				reflect.DeepEqual(tSet.GetOptMap("label"), labelsValue) &&
+				tSet.GetFlag("verbose") == verbose &&
+				tSet.GetArgString("first") == first &&
+				tSet.GetArgFloat("second") == second &&
+				len(tSet.GetPassthrough()) == len(passthrough) &&
+				(len(passthrough) == 0 || reflect.DeepEqual(tSet.GetPassthrough(), passthrough)) &&
+				reflect.DeepEqual(tSet.Argv(), argv)
+		}
+
+		for _, str := range []string{"", " ", "-", "--", "-v", "--help", "-5", "a b", "--name=a", "=", "a\nb", "-x y"} {
+			if !roundTrip(str, -1, -0.5, map[string]string{"k": str}, true, str, -2, []string{str, str}) {
+				t.Errorf("strict order %v: failed on input %q", strictOrder, str)
+			}
+		}
+
+		if err := quick.Check(roundTrip, nil); err != nil {
+			t.Errorf("strict order %v: %v", strictOrder, err)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		argv []string
+		str  string
+	}{
+		{[]string{"app", "--name=John", "-5"}, "app --name=John -5"},
+		{[]string{"app", "--name", "John Doe"}, "app --name 'John Doe'"},
+		{[]string{"app", ""}, "app ''"},
+		{[]string{"app", "it's", "$HOME"}, `app 'it'\''s' '$HOME'`},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			str := ShellQuote(test.argv)
+
+			if str != test.str {
+				t.Errorf("got %v, want %v", str, test.str)
+			}
+		})
+	}
+}
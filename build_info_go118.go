@@ -0,0 +1,36 @@
+//go:build go1.18
+// +build go1.18
+
+package cfop
+
+import "runtime/debug"
+
+// readDebugBuildInfo reads the build info embedded in the binary,
+// including the VCS settings recorded by the go command.
+func readDebugBuildInfo() (buildInfo, bool) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return buildInfo{}, false
+	}
+
+	return newBuildInfo(bi), true
+}
+
+// newBuildInfo converts bi into a buildInfo.
+func newBuildInfo(bi *debug.BuildInfo) buildInfo {
+	info := buildInfo{
+		Module:    bi.Main.Path,
+		GoVersion: bi.GoVersion,
+	}
+
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+package cfop
+
+import (
+	"runtime/debug"
+	"strconv"
+	"testing"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	tests := []struct {
+		bi   debug.BuildInfo
+		info buildInfo
+	}{
+		{
+			debug.BuildInfo{
+				GoVersion: "go1.18",
+				Main:      debug.Module{Path: "example.com/testing"},
+			},
+			buildInfo{Module: "example.com/testing", GoVersion: "go1.18"},
+		},
+		{
+			debug.BuildInfo{
+				GoVersion: "go1.18",
+				Main:      debug.Module{Path: "example.com/testing"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			buildInfo{
+				Module:    "example.com/testing",
+				Revision:  "abc123",
+				Modified:  true,
+				GoVersion: "go1.18",
+			},
+		},
+		{
+			debug.BuildInfo{
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			buildInfo{Revision: "abc123"},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			info := newBuildInfo(&test.bi)
+
+			if info != test.info {
+				t.Errorf("got %+v, want %+v", info, test.info)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !go1.18
+// +build !go1.18
+
+package cfop
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// readDebugBuildInfo reads the build info embedded in the binary. Go
+// versions before 1.18 don't record VCS settings, so the revision is
+// always empty.
+func readDebugBuildInfo() (buildInfo, bool) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return buildInfo{}, false
+	}
+
+	return buildInfo{
+		Module:    bi.Main.Path,
+		GoVersion: runtime.Version(),
+	}, true
+}
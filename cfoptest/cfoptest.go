@@ -0,0 +1,236 @@
+/*
+Package cfoptest provides utilities for testing CLIs built with cfop
+in-process, without building and running their binaries.
+
+A test runs an App against a list of terms and makes assertions on the
+result:
+
+	res := cfoptest.Run(t, app, "add", "user", "--admin", "John")
+
+	res.AssertExitCode(t, 0)
+	res.AssertPath(t, "app", "add", "user")
+	res.AssertFlag(t, "admin", true)
+	res.AssertArg(t, "name", "John")
+
+Help messages and other outputs can be compared against golden files
+stored in the testdata directory, which are updated when the tests are
+run with the -cfoptest.update flag:
+
+	cfoptest.Golden(t, "add-help", cfoptest.Run(t, app, "add", "--help").Stdout)
+*/
+package cfoptest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/efreitasn/cfop"
+)
+
+var update = flag.Bool("cfoptest.update", false, "update the golden files used by cfoptest")
+
+// Result is the result of running an App.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Err is the error returned by the App.
+	Err error
+	// Cmd is the cmd whose function was called. It's nil if no function
+	// was called, e.g. when a parse error happens.
+	Cmd *cfop.Cmd
+	// Path is the name of each cmd executed to reach Cmd.
+	Path []string
+	// Terms is the terms set passed to Cmd's function.
+	Terms *cfop.CmdTermsSet
+}
+
+// Run runs app with args, which are the terms after the root cmd's
+// name, and returns the result. app isn't changed, since the streams and
+// the middleware used to record the result are set in a copy of it.
+// Unless app.Color and app.HelpWidth are set, the output isn't styled
+// and help messages are wrapped at 80 columns, so that they don't depend
+// on the environment.
+func Run(t testing.TB, app cfop.App, args ...string) *Result {
+	t.Helper()
+
+	return RunWithStdin(t, app, "", args...)
+}
+
+// RunWithStdin is like Run, but stdin is used as the input stream.
+func RunWithStdin(t testing.TB, app cfop.App, stdin string, args ...string) *Result {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	res := &Result{}
+
+	app.IO = cfop.IOStreams{
+		In:  strings.NewReader(stdin),
+		Out: &stdout,
+		Err: &stderr,
+	}
+
+	if app.Color == "" {
+		app.Color = cfop.ColorNever
+	}
+
+	if app.HelpWidth <= 0 {
+		app.HelpWidth = 80
+	}
+	app.Middlewares = append(
+		[]cfop.Middleware{
+			func(next cfop.Handler) cfop.Handler {
+				return func(ctx context.Context, cts *cfop.CmdTermsSet) error {
+					res.Cmd = cts.Cmd()
+					res.Path = cts.Path()
+					res.Terms = cts
+
+					return next(ctx, cts)
+				}
+			},
+		},
+		app.Middlewares...,
+	)
+
+	res.Err = app.RunContext(context.Background(), args)
+	res.ExitCode = app.HandleError(res.Err)
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+
+	return res
+}
+
+// AssertExitCode asserts that the exit code is code.
+func (r *Result) AssertExitCode(t testing.TB, code int) {
+	t.Helper()
+
+	if r.ExitCode != code {
+		t.Errorf("got exit code %v, want %v (stderr: %q)", r.ExitCode, code, r.Stderr)
+	}
+}
+
+// AssertCmd asserts that the cmd whose function was called is c.
+func (r *Result) AssertCmd(t testing.TB, c *cfop.Cmd) {
+	t.Helper()
+
+	if r.Cmd != c {
+		t.Errorf("got cmd at %q, want another cmd", r.Path)
+	}
+}
+
+// AssertPath asserts that the cmds executed are the ones in path,
+// starting with the root cmd's name.
+func (r *Result) AssertPath(t testing.TB, path ...string) {
+	t.Helper()
+
+	if !reflect.DeepEqual(r.Path, path) {
+		t.Errorf("got path %q, want %q", r.Path, path)
+	}
+}
+
+// AssertOpt asserts that the value of the option named name is want,
+// which must be a string, an int, a float64 or, for map options, a
+// map[string]interface{}.
+func (r *Result) AssertOpt(t testing.TB, name string, want interface{}) {
+	t.Helper()
+
+	if r.Terms == nil {
+		t.Fatalf("no cmd function was called (stderr: %q)", r.Stderr)
+	}
+
+	var got interface{}
+
+	switch want.(type) {
+	case string:
+		got = r.Terms.GetOptString(name)
+	case int:
+		got = r.Terms.GetOptInt(name)
+	case float64:
+		got = r.Terms.GetOptFloat(name)
+	case map[string]interface{}:
+		got = r.Terms.GetOptMap(name)
+	default:
+		t.Fatalf("unsupported type %T for option %v", want, name)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v for option %v, want %v", got, name, want)
+	}
+}
+
+// AssertFlag asserts that the value of the flag named name is want.
+func (r *Result) AssertFlag(t testing.TB, name string, want bool) {
+	t.Helper()
+
+	if r.Terms == nil {
+		t.Fatalf("no cmd function was called (stderr: %q)", r.Stderr)
+	}
+
+	if got := r.Terms.GetFlag(name); got != want {
+		t.Errorf("got %v for flag %v, want %v", got, name, want)
+	}
+}
+
+// AssertArg asserts that the value of the argument named name is want,
+// which must be a string, an int or a float64.
+func (r *Result) AssertArg(t testing.TB, name string, want interface{}) {
+	t.Helper()
+
+	if r.Terms == nil {
+		t.Fatalf("no cmd function was called (stderr: %q)", r.Stderr)
+	}
+
+	var got interface{}
+
+	switch want.(type) {
+	case string:
+		got = r.Terms.GetArgString(name)
+	case int:
+		got = r.Terms.GetArgInt(name)
+	case float64:
+		got = r.Terms.GetArgFloat(name)
+	default:
+		t.Fatalf("unsupported type %T for argument %v", want, name)
+	}
+
+	if got != want {
+		t.Errorf("got %v for argument %v, want %v", got, name, want)
+	}
+}
+
+// Golden compares got with the content of testdata/name.golden. If the
+// tests are run with the -cfoptest.update flag, the file is written with
+// got instead.
+func Golden(t testing.TB, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -cfoptest.update to create it)", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("got %q, want %q from %v", got, want, path)
+	}
+}
@@ -0,0 +1,110 @@
+package cfoptest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/efreitasn/cfop"
+)
+
+func newTestApp() (cfop.App, *cfop.Cmd) {
+	userCmd := cfop.NewCmd(cfop.CmdConfig{
+		CtxFn: func(ctx context.Context, cts *cfop.CmdTermsSet) error {
+			if cts.GetArgString("name") == "fail" {
+				return cfop.ErrExit{Code: 3, Err: errors.New("failed")}
+			}
+
+			in, err := ioutil.ReadAll(cts.Stdin())
+			if err != nil {
+				return err
+			}
+
+			cts.Stdout().Write([]byte("added " + cts.GetArgString("name") + string(in)))
+
+			return nil
+		},
+		Options: []cfop.CmdOption{
+			{Name: "age", T: cfop.TermInt},
+			{Name: "label", T: cfop.TermString, Map: true},
+		},
+		Flags: []cfop.CmdFlag{
+			{Name: "admin", Description: "whether the user is an admin"},
+		},
+		Args: []cfop.CmdArg{
+			{Name: "name", T: cfop.TermString},
+		},
+	})
+
+	return cfop.App{
+		Name: "app",
+		Root: cfop.NewSubcmdsSet(
+			cfop.Subcmd{
+				Name:        "add",
+				Description: "Adds something",
+				Parser: cfop.NewSubcmdsSet(
+					cfop.Subcmd{
+						Name:        "user",
+						Description: "Adds user",
+						Parser:      userCmd,
+					},
+				),
+			},
+		),
+	}, userCmd
+}
+
+func TestRun(t *testing.T) {
+	app, userCmd := newTestApp()
+
+	res := RunWithStdin(t, app, "!", "add", "user", "--admin", "--age", "20", "--label", "k=v", "John")
+
+	res.AssertExitCode(t, 0)
+	res.AssertCmd(t, userCmd)
+	res.AssertPath(t, "app", "add", "user")
+	res.AssertFlag(t, "admin", true)
+	res.AssertOpt(t, "age", 20)
+	res.AssertOpt(t, "label", map[string]interface{}{"k": "v"})
+	res.AssertArg(t, "name", "John")
+
+	if res.Stdout != "added John!" {
+		t.Errorf("got %q, want %q", res.Stdout, "added John!")
+	}
+
+	if app.IO.Out != nil || len(app.Middlewares) != 0 {
+		t.Error("app was changed")
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	app, _ := newTestApp()
+
+	res := Run(t, app, "add", "user", "--what")
+
+	res.AssertExitCode(t, cfop.ExitCodeParseError)
+
+	if res.Cmd != nil || res.Terms != nil {
+		t.Errorf("got cmd at %q, want none", res.Path)
+	}
+
+	Golden(t, "parse-error", res.Stderr)
+
+	res = Run(t, app, "add", "user", "fail")
+
+	res.AssertExitCode(t, 3)
+	res.AssertPath(t, "app", "add", "user")
+
+	if res.Stderr != "app: failed\n" {
+		t.Errorf("got %q, want %q", res.Stderr, "app: failed\n")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	app, _ := newTestApp()
+
+	res := Run(t, app, "add", "--help")
+
+	res.AssertExitCode(t, 0)
+	Golden(t, "add-help", res.Stdout)
+}
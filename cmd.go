@@ -1,8 +1,13 @@
 package cfop
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+
+	"github.com/efreitasn/customo"
 )
 
 // TermType is the type of a cmd term.
@@ -15,12 +20,29 @@ const (
 	TermString TermType = "string"
 )
 
+// DuplicateKeyPolicy is the policy applied when a key of a map option
+// is provided more than once.
+type DuplicateKeyPolicy string
+
+// Duplicate key policies.
+const (
+	// DuplicateKeyOverwrite keeps the last value provided for a key.
+	DuplicateKeyOverwrite DuplicateKeyPolicy = "overwrite"
+	// DuplicateKeyKeepFirst keeps the first value provided for a key.
+	DuplicateKeyKeepFirst DuplicateKeyPolicy = "keep-first"
+	// DuplicateKeyError returns an error when a key is provided more than once.
+	DuplicateKeyError DuplicateKeyPolicy = "error"
+)
+
 // CmdTermsSet is a set of terms passed alongside a cmd.
 type CmdTermsSet struct {
 	cmd           *Cmd
 	optionsValues map[string]interface{}
 	flagsValues   map[string]bool
 	argsValues    map[string]interface{}
+	passthrough   []string
+	path          []string
+	streams       IOStreams
 }
 
 // GetOptString returns the value of an option of type string.
@@ -74,6 +96,24 @@ func (ct *CmdTermsSet) GetOptFloat(name string) float64 {
 	return (value).(float64)
 }
 
+// GetOptMap returns the value of a map option.
+// name can be either the option's name or the option's alias.
+// The values of the map are of the option's type.
+// If the option doesn't exist, its zero value is returned.
+func (ct *CmdTermsSet) GetOptMap(name string) map[string]interface{} {
+	opt := ct.cmd.getOption(name)
+	if opt == nil || !opt.Map {
+		return nil
+	}
+
+	value, ok := ct.optionsValues[opt.Name]
+	if !ok {
+		return nil
+	}
+
+	return (value).(map[string]interface{})
+}
+
 // GetFlag returns the value of a flag.
 // name can be either the flag's name or the flag's alias.
 // If the flag doesn't exist, its zero value is returned.
@@ -119,6 +159,99 @@ func (ct *CmdTermsSet) GetArgFloat(name string) float64 {
 	return (ct.argsValues[name]).(float64)
 }
 
+// setOptionValue validates optValueStr against opt's type and stores it.
+// optName and isAlias are the name and kind used in the term, so that
+// errors refer to what the user typed.
+func (ct *CmdTermsSet) setOptionValue(opt *CmdOption, optName string, isAlias bool, optValueStr string) error {
+	if !opt.Map {
+		optValue, validValue := isValueValidForTermType(opt.T, optValueStr)
+		if !validValue {
+			return ErrOptionExpectsDifferentValueType{
+				OptionName:   optName,
+				IsAlias:      isAlias,
+				ExpectedType: opt.T,
+			}
+		}
+
+		ct.optionsValues[opt.Name] = optValue
+
+		return nil
+	}
+
+	key, valueStr, ok := splitKeyValuePair(optValueStr)
+	if !ok {
+		return ErrOptionExpectsKeyValuePair{
+			OptionName: optName,
+			IsAlias:    isAlias,
+		}
+	}
+
+	value, validValue := isValueValidForTermType(opt.T, valueStr)
+	if !validValue {
+		return ErrOptionExpectsDifferentValueType{
+			OptionName:   optName,
+			IsAlias:      isAlias,
+			ExpectedType: opt.T,
+		}
+	}
+
+	m, ok := ct.optionsValues[opt.Name].(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+		ct.optionsValues[opt.Name] = m
+	}
+
+	if _, exists := m[key]; exists {
+		switch opt.DuplicateKeys {
+		case DuplicateKeyKeepFirst:
+			return nil
+		case DuplicateKeyError:
+			return ErrDuplicateMapOptionKey{
+				OptionName: optName,
+				IsAlias:    isAlias,
+				Key:        key,
+			}
+		}
+	}
+
+	m[key] = value
+
+	return nil
+}
+
+// Cmd returns the cmd whose terms are in the set.
+func (ct *CmdTermsSet) Cmd() *Cmd {
+	return ct.cmd
+}
+
+// Path returns the name of each cmd executed to reach the cmd, starting
+// with the root cmd's name.
+func (ct *CmdTermsSet) Path() []string {
+	return append([]string(nil), ct.path...)
+}
+
+// Stdin returns the input stream of the invocation.
+func (ct *CmdTermsSet) Stdin() io.Reader {
+	return ct.streams.withDefaults().In
+}
+
+// Stdout returns the output stream of the invocation.
+func (ct *CmdTermsSet) Stdout() io.Writer {
+	return ct.streams.withDefaults().Out
+}
+
+// Stderr returns the error stream of the invocation.
+func (ct *CmdTermsSet) Stderr() io.Writer {
+	return ct.streams.withDefaults().Err
+}
+
+// GetPassthrough returns the terms that came after every argument was
+// provided in a cmd with strict order. For any other cmd, it's always
+// empty.
+func (ct *CmdTermsSet) GetPassthrough() []string {
+	return ct.passthrough
+}
+
 // CmdOption is a cmd option.
 type CmdOption struct {
 	// Name is used with --, is case-sensitive and cannot start with -.
@@ -126,9 +259,21 @@ type CmdOption struct {
 	// Alias is used with -, is case-senstitive and cannot start with -.
 	Alias       string
 	Description string
-	// T is the type of the option.
+	// T is the type of the option. If Map is true, it's the type of
+	// the map's values.
 	T        TermType
 	Required bool
+	// Map indicates whether the option holds key/value pairs, e.g.
+	// --label k=v. Each occurrence of the option adds a pair to the map.
+	Map bool
+	// DuplicateKeys is the policy applied when a key is provided more
+	// than once to a map option. It defaults to DuplicateKeyOverwrite
+	// and can only be provided if Map is true.
+	DuplicateKeys DuplicateKeyPolicy
+	// Group is the title of the section of the help message in which
+	// the option is listed, e.g. Output options. If it's empty, the
+	// option is listed with the other options.
+	Group string
 }
 
 // CmdFlag is a cmd flag.
@@ -138,6 +283,10 @@ type CmdFlag struct {
 	// Alias is used with -, is case-senstive and cannot start with -.
 	Alias       string
 	Description string
+	// Group is the title of the section of the help message in which
+	// the flag is listed. If it's empty, the flag is listed with the
+	// other flags.
+	Group string
 }
 
 // CmdArg is a cmd argument.
@@ -151,39 +300,120 @@ type CmdArg struct {
 
 // CmdConfig is a config used to create a cmd.
 type CmdConfig struct {
-	Fn      func(*CmdTermsSet)
+	Fn func(*CmdTermsSet)
+	// CtxFn is an alternative to Fn that receives the context passed to
+	// InitContext and returns an error, which is then returned by it.
+	// Either Fn or CtxFn must be provided.
+	CtxFn   func(context.Context, *CmdTermsSet) error
 	Options []CmdOption
 	Flags   []CmdFlag
 	Args    []CmdArg
+	// NoNegativeNumbers disables taking terms such as -5 or -2.5 as the
+	// value of an int or float option or argument. It's useful for CLIs
+	// that use digits as aliases. Even when it's false, a term matching
+	// an existing alias is always taken as that alias.
+	NoNegativeNumbers bool
+	// AllowAbbreviations allows options and flags to be referenced by
+	// an unambiguous prefix of their names, e.g. --verb for --verbose.
+	// Aliases are never abbreviated.
+	AllowAbbreviations bool
+	// StrictOrder stops the parsing of options and flags at the first
+	// argument, like getopt's + mode. Every term after it is taken as an
+	// argument, including --help, and the ones left after every argument
+	// is provided are available via CmdTermsSet.GetPassthrough.
+	StrictOrder bool
+	// CollectErrors makes the parsing go through every term and check,
+	// even after an error is found. If there are any errors, they're all
+	// returned in an ErrParseAggregate and the cmd's function isn't called.
+	CollectErrors bool
+	// Groups is the order in which the groups of the options and flags
+	// are listed in the help message. Groups not in it are listed after
+	// the ones in it, in the order they first appear.
+	Groups []string
+	// LongDescription is printed in the help message after the cmd's
+	// description.
+	LongDescription string
+	// Examples are listed in the Examples section of the help message.
+	Examples []CmdExample
+	// SeeAlso are the paths of related cmds, e.g. app image ls, listed
+	// in the See also section of the help message. Each path starts with
+	// the root cmd's name and must reach a cmd or subcmds set, which can
+	// be checked with App.ValidateSeeAlso.
+	SeeAlso []string
+	// Footer is printed at the end of the help message.
+	Footer string
+}
+
+// CmdExample is an example of how to use a cmd.
+type CmdExample struct {
+	// Cmd is the command line of the example, e.g. app foo --bar.
+	Cmd         string
+	Description string
 }
 
 // Cmd is a command.
 type Cmd struct {
-	fn              func(*CmdTermsSet)
+	fn              Handler
+	hooks           hooks
 	options         map[string]*CmdOption
 	optionsByAlias  map[string]*CmdOption
+	optionsByPos    []*CmdOption
 	requiredOptions []*CmdOption
 	flags           map[string]*CmdFlag
 	flagsByAlias    map[string]*CmdFlag
+	flagsByPos      []*CmdFlag
 	argsByPos       []*CmdArg
 	argsByName      map[string]*CmdArg
+	// noNegativeNumbers is CmdConfig.NoNegativeNumbers.
+	noNegativeNumbers bool
+	// allowAbbreviations is CmdConfig.AllowAbbreviations.
+	allowAbbreviations bool
+	// strictOrder is CmdConfig.StrictOrder.
+	strictOrder bool
+	// collectErrors is CmdConfig.CollectErrors.
+	collectErrors bool
+	// groups is CmdConfig.Groups.
+	groups []string
+	// longDescription is CmdConfig.LongDescription.
+	longDescription string
+	// examples is CmdConfig.Examples.
+	examples []CmdExample
+	// seeAlso is CmdConfig.SeeAlso.
+	seeAlso []string
+	// footer is CmdConfig.Footer.
+	footer string
 }
 
 // NewCmd creates a cmd.
 // If an invalid flag name or option name is passed or a function isn't passed, it panics.
 func NewCmd(cc CmdConfig) *Cmd {
 	options := make(map[string]*CmdOption)
+	optionsByPos := make([]*CmdOption, 0, len(cc.Options))
 	requiredOptions := make([]*CmdOption, 0)
 	optionsByAlias := make(map[string]*CmdOption)
 	flags := make(map[string]*CmdFlag)
 	flagsByAlias := make(map[string]*CmdFlag)
+	flagsByPos := make([]*CmdFlag, 0, len(cc.Flags))
 	argsByName := make(map[string]*CmdArg, len(cc.Args))
 	argsByPos := make([]*CmdArg, 0, len(cc.Args))
 
-	if cc.Fn == nil {
+	if cc.Fn == nil && cc.CtxFn == nil {
 		panic(ErrMissingCmdFn)
 	}
 
+	if cc.Fn != nil && cc.CtxFn != nil {
+		panic(ErrMultipleCmdFns)
+	}
+
+	fn := cc.CtxFn
+	if fn == nil {
+		fn = func(ctx context.Context, cts *CmdTermsSet) error {
+			cc.Fn(cts)
+
+			return nil
+		}
+	}
+
 	if cc.Options != nil {
 		for i := range cc.Options {
 			opt := cc.Options[i]
@@ -198,7 +428,20 @@ func NewCmd(cc CmdConfig) *Cmd {
 				panic(ErrMissingTermTypeForTerm{Term: opt.Name})
 			}
 
+			if opt.DuplicateKeys != "" && !opt.Map {
+				panic(ErrDuplicateKeysWithoutMap{Option: opt.Name})
+			}
+
+			switch opt.DuplicateKeys {
+			case "":
+				opt.DuplicateKeys = DuplicateKeyOverwrite
+			case DuplicateKeyOverwrite, DuplicateKeyKeepFirst, DuplicateKeyError:
+			default:
+				panic(ErrInvalidDuplicateKeyPolicy)
+			}
+
 			options[opt.Name] = &opt
+			optionsByPos = append(optionsByPos, &opt)
 
 			if opt.Required {
 				requiredOptions = append(requiredOptions, &opt)
@@ -219,6 +462,7 @@ func NewCmd(cc CmdConfig) *Cmd {
 			}
 
 			flags[flag.Name] = &flag
+			flagsByPos = append(flagsByPos, &flag)
 
 			if flag.Alias != "" {
 				flagsByAlias[flag.Alias] = &flag
@@ -244,14 +488,26 @@ func NewCmd(cc CmdConfig) *Cmd {
 	}
 
 	return &Cmd{
-		fn:              cc.Fn,
+		fn:              fn,
 		options:         options,
 		requiredOptions: requiredOptions,
 		optionsByAlias:  optionsByAlias,
+		optionsByPos:    optionsByPos,
 		flags:           flags,
 		flagsByAlias:    flagsByAlias,
+		flagsByPos:      flagsByPos,
 		argsByPos:       argsByPos,
 		argsByName:      argsByName,
+
+		noNegativeNumbers:  cc.NoNegativeNumbers,
+		allowAbbreviations: cc.AllowAbbreviations,
+		strictOrder:        cc.StrictOrder,
+		collectErrors:      cc.CollectErrors,
+		groups:             cc.Groups,
+		longDescription:    cc.LongDescription,
+		examples:           cc.Examples,
+		seeAlso:            cc.SeeAlso,
+		footer:             cc.Footer,
 	}
 }
 
@@ -300,92 +556,294 @@ func (c *Cmd) getArgByName(name string) *CmdArg {
 	return arg
 }
 
+// findOptionOrFlag returns the option or the flag referenced by name.
+// If c allows abbreviations and name isn't an alias, name can also be an
+// unambiguous prefix of an option's or flag's name. If it's ambiguous, an
+// error is returned. If nothing is found, both are nil.
+func (c *Cmd) findOptionOrFlag(name string, isAlias bool) (*CmdOption, *CmdFlag, error) {
+	if isAlias {
+		return c.optionsByAlias[name], c.flagsByAlias[name], nil
+	}
+
+	opt, f := c.options[name], c.flags[name]
+	if opt != nil || f != nil || !c.allowAbbreviations {
+		return opt, f, nil
+	}
+
+	var candidates []string
+
+	for optName := range c.options {
+		if strings.HasPrefix(optName, name) {
+			candidates = append(candidates, optName)
+			opt = c.options[optName]
+		}
+	}
+
+	for flagName := range c.flags {
+		if strings.HasPrefix(flagName, name) {
+			candidates = append(candidates, flagName)
+			f = c.flags[flagName]
+		}
+	}
+
+	if len(candidates) > 1 {
+		sort.Strings(candidates)
+
+		return nil, nil, ErrAmbiguousOptionOrFlag{
+			OptionOrFlagName: name,
+			Candidates:       candidates,
+		}
+	}
+
+	return opt, f, nil
+}
+
+// isNegativeNumberValue returns whether str, which looks like an alias,
+// should be taken as a negative number value of type t, e.g. -5 for an
+// option or argument of type int. It's never the case if str is an
+// existing alias.
+func (c *Cmd) isNegativeNumberValue(str string, t TermType) bool {
+	if c.noNegativeNumbers || (t != TermInt && t != TermFloat) || !isNegativeNumber(str) {
+		return false
+	}
+
+	alias := str[1:]
+
+	return c.optionsByAlias[alias] == nil && c.flagsByAlias[alias] == nil
+}
+
+// parseArg parses str as the next argument. If every argument was already
+// provided and c has strict order, str is taken as a passthrough term.
+func (c *Cmd) parseArg(tSet *CmdTermsSet, str string) error {
+	nextArgPos := len(tSet.argsValues)
+
+	arg := c.getArgByPos(nextArgPos)
+	if arg == nil {
+		if c.strictOrder {
+			tSet.passthrough = append(tSet.passthrough, str)
+
+			return nil
+		}
+
+		return ErrUnexpectedArgument{Argument: str}
+	}
+
+	argVal, valid := isValueValidForTermType(arg.T, str)
+	if !valid {
+		// The argument is still considered provided, so that any
+		// subsequent term is matched against the next argument.
+		tSet.argsValues[arg.Name] = nil
+
+		return ErrArgumentExpectsDifferentValueType{
+			ArgumentPos:  nextArgPos,
+			ArgumentName: arg.Name,
+			ExpectedType: arg.T,
+			Value:        str,
+		}
+	}
+
+	tSet.argsValues[arg.Name] = argVal
+
+	return nil
+}
+
 // Parse parses a slice of strings.
 func (c *Cmd) Parse(pp parentParser, strs []string) error {
+	tSet, err := c.parse(pp, strs)
+	if err != nil || tSet == nil {
+		return err
+	}
+
+	chain := append(pp.hooks[:len(pp.hooks):len(pp.hooks)], &c.hooks)
+
+	return pp.resolve(ParseResult{
+		Path:  tSet.Path(),
+		Cmd:   c,
+		Terms: tSet,
+	}, func(ctx context.Context) error {
+		return runHooks(ctx, chain, c.fn, tSet)
+	})
+}
+
+// Before registers hooks to be called before c's function. If one of
+// them returns an error, the execution stops and the error is returned.
+func (c *Cmd) Before(hooks ...Handler) {
+	c.hooks.before = append(c.hooks.before, hooks...)
+}
+
+// After registers hooks to be called after c's function, as long as it
+// doesn't return an error.
+func (c *Cmd) After(hooks ...Handler) {
+	c.hooks.after = append(c.hooks.after, hooks...)
+}
+
+// Use registers middlewares wrapping c's function. The first middleware
+// registered is the outermost one.
+func (c *Cmd) Use(middlewares ...Middleware) {
+	c.hooks.middlewares = append(c.hooks.middlewares, middlewares...)
+}
+
+// parse parses strs into a terms set. If the help flag is found, printing
+// the help message is resolved and a nil terms set is returned.
+func (c *Cmd) parse(pp parentParser, strs []string) (*CmdTermsSet, error) {
 	tSet := &CmdTermsSet{
 		cmd:           c,
+		path:          pp.cmds,
+		streams:       pp.streams,
 		optionsValues: make(map[string]interface{}),
 		argsValues:    make(map[string]interface{}),
 		flagsValues:   make(map[string]bool),
 	}
 	i := 0
 
+	// optionsEnded indicates whether every remaining term must be taken
+	// as an argument, which happens after -- or, in strict order, after
+	// the first one.
+	optionsEnded := pp.optionsEnded
+
+	var errs []ErrParse
+
+	// providedOptions holds the name of every option provided, even with
+	// an invalid value, so that it isn't reported as missing too.
+	providedOptions := make(map[string]bool)
+
+	// fail records err, which happened at strs[errI], and returns whether
+	// the parsing must stop.
+	fail := func(errI int, err error) bool {
+		errs = append(errs, newErrParse(pp, c.usage(pp), strs, errI, err))
+
+		return !c.collectErrors
+	}
+
+	// takeColorOption parses the built-in --color option at strs[i],
+	// moves i past it and returns whether the parsing must stop.
+	takeColorOption := func() bool {
+		mode, end, err := parseColorOption(strs, i)
+		i = end + 1
+
+		if err != nil {
+			return fail(end, err)
+		}
+
+		pp.colorMode = mode
+
+		return false
+	}
+
+terms:
 	for i < len(strs) {
 		str := strs[i]
 
+		if optionsEnded {
+			if err := c.parseArg(tSet, str); err != nil && fail(i, err) {
+				break terms
+			}
+
+			i++
+			continue
+		}
+
+		// -- ends the options, so that every term after it is taken as
+		// an argument, even the ones starting with -.
+		if str == "--" {
+			optionsEnded = true
+
+			i++
+			continue
+		}
+
 		if isHelpFlag(str) {
-			printHelp(c, pp)
+			mode, errI, err := helpColorMode(pp, c, strs, i+1)
+			if err != nil {
+				return nil, newErrParse(pp, c.usage(pp), strs, errI, err)
+			}
 
-			return nil
+			pp.colorMode = mode
+
+			return nil, pp.resolve(ParseResult{
+				Path: append([]string(nil), pp.cmds...),
+				Cmd:  c,
+				Help: true,
+			}, func(context.Context) error {
+				return printHelp(c, pp)
+			})
+		}
+
+		// The built-in --color option is looked for before abbreviations,
+		// so that it isn't taken as one of an option or flag of c.
+		if pp.isColorOption(str) && !hasColorOptionOrFlag(c) {
+			if takeColorOption() {
+				break terms
+			}
+
+			continue
 		}
 
 		if isOptionWithValue(str) {
 			optName, isAlias := extractOptionName(str)
 
-			var opt *CmdOption
-
-			if isAlias {
-				opt = c.optionsByAlias[optName]
-			} else {
-				opt = c.options[optName]
+			opt, _, err := c.findOptionOrFlag(optName, isAlias)
+			if opt != nil {
+				providedOptions[opt.Name] = true
 			}
 
-			if opt == nil {
-				return ErrUnexpectedOption{
+			if err == nil && opt == nil {
+				err = ErrUnexpectedOption{
 					OptionName: optName,
 					IsAlias:    isAlias,
 				}
 			}
 
-			optValueStr := extractOptionValue(str)
-			if optValueStr == "" {
-				return ErrOptionsExpectsAValue{
-					OptionName: optName,
-					IsAlias:    isAlias,
+			if err == nil {
+				optValueStr := extractOptionValue(str)
+				if optValueStr == "" {
+					err = ErrOptionsExpectsAValue{
+						OptionName: optName,
+						IsAlias:    isAlias,
+					}
+				} else {
+					err = tSet.setOptionValue(opt, optName, isAlias, optValueStr)
 				}
 			}
 
-			optValue, validValue := isValueValidForTermType(opt.T, optValueStr)
-			if !validValue {
-				return ErrOptionExpectsDifferentValueType{
-					OptionName:   optName,
-					IsAlias:      isAlias,
-					ExpectedType: opt.T,
-				}
+			if err != nil && fail(i, err) {
+				break terms
 			}
 
-			tSet.optionsValues[opt.Name] = optValue
-
 			i++
 			continue
 		}
 
-		if isOptionWithoutValue(str) {
+		var nextArgT TermType
+		if arg := c.getArgByPos(len(tSet.argsValues)); arg != nil {
+			nextArgT = arg.T
+		}
+
+		if isOptionWithoutValue(str) && !c.isNegativeNumberValue(str, nextArgT) {
 			optName, isAlias := extractOptionName(str)
 
-			var opt *CmdOption
+			opt, f, err := c.findOptionOrFlag(optName, isAlias)
+			if err != nil {
+				if fail(i, err) {
+					break terms
+				}
 
-			if isAlias {
-				opt = c.optionsByAlias[optName]
-			} else {
-				opt = c.options[optName]
+				i++
+				continue
 			}
 
 			if opt == nil {
 				// An option without value could be a flag
-				var f *CmdFlag
-
-				if isAlias {
-					f = c.flagsByAlias[optName]
-				} else {
-					f = c.flags[optName]
-				}
-
 				if f == nil {
-					return ErrUnexpectedOptionOrFlag{
+					if fail(i, ErrUnexpectedOptionOrFlag{
 						OptionOrFlagName: optName,
 						IsAlias:          isAlias,
+					}) {
+						break terms
 					}
+
+					i++
+					continue
 				}
 
 				tSet.flagsValues[f.Name] = true
@@ -394,217 +852,309 @@ func (c *Cmd) Parse(pp parentParser, strs []string) error {
 				continue
 			}
 
-			if len(strs) > (i+1) && !isOptionWithValue(strs[i+1]) && !isOptionWithoutValue(strs[i+1]) {
-				optValueStr := strs[i+1]
-				optValue, validValue := isValueValidForTermType(opt.T, optValueStr)
-				if !validValue {
-					return ErrOptionExpectsDifferentValueType{
-						OptionName:   optName,
-						IsAlias:      isAlias,
-						ExpectedType: opt.T,
-					}
-				}
+			providedOptions[opt.Name] = true
 
-				tSet.optionsValues[opt.Name] = optValue
+			if len(strs) > (i+1) &&
+				((!isOptionWithValue(strs[i+1]) && !isOptionWithoutValue(strs[i+1])) ||
+					(!opt.Map && c.isNegativeNumberValue(strs[i+1], opt.T))) {
+				if err := tSet.setOptionValue(opt, optName, isAlias, strs[i+1]); err != nil && fail(i+1, err) {
+					break terms
+				}
 
 				i += 2
 
 				continue
 			}
 
-			return ErrOptionsExpectsAValue{
+			if fail(i, ErrOptionsExpectsAValue{
 				OptionName: optName,
 				IsAlias:    isAlias,
+			}) {
+				break terms
 			}
+
+			i++
+			continue
 		}
 
 		// If it reaches this part, it means it's not an option with value
 		// (--opt=value) nor an option without value or flag (--opt). This
 		// way, we consider it as an argument.
-		nextArgPos := len(tSet.argsValues)
+		if err := c.parseArg(tSet, str); err != nil && fail(i, err) {
+			break terms
+		}
 
-		if len(c.argsByName) >= (nextArgPos + 1) {
-			arg := c.argsByPos[nextArgPos]
-			argVal, valid := isValueValidForTermType(arg.T, str)
+		optionsEnded = c.strictOrder
 
-			if valid {
-				tSet.argsValues[arg.Name] = argVal
+		i++
+	}
 
-				i++
-				continue
-			} else {
-				return ErrArgumentExpectsDifferentValueType{
-					ArgumentPos:  nextArgPos,
-					ArgumentName: arg.Name,
-					ExpectedType: arg.T,
-					Value:        str,
-				}
-			}
-		} else {
-			return ErrUnexpectedArgument{Argument: str}
-		}
+	if len(errs) > 0 && !c.collectErrors {
+		return nil, errs[0]
 	}
 
 	if len(tSet.argsValues) != len(c.argsByName) {
-		return ErrMissingArguments
+		missingArgs := make([]MissingArgument, 0, len(c.argsByPos)-len(tSet.argsValues))
+
+		for pos := len(tSet.argsValues); pos < len(c.argsByPos); pos++ {
+			arg := c.argsByPos[pos]
+
+			missingArgs = append(missingArgs, MissingArgument{
+				Name: arg.Name,
+				Pos:  pos,
+				T:    arg.T,
+			})
+		}
+
+		if fail(-1, ErrMissingArguments{
+			Args:  missingArgs,
+			Usage: c.usage(pp),
+		}) {
+			return nil, errs[0]
+		}
 	}
 
 	for _, opt := range c.requiredOptions {
-		if _, ok := tSet.optionsValues[opt.Name]; !ok {
-			return ErrRequiredOptionNotProvided{OptionName: opt.Name}
+		if !providedOptions[opt.Name] {
+			if fail(-1, ErrRequiredOptionNotProvided{OptionName: opt.Name}) {
+				return nil, errs[0]
+			}
 		}
 	}
 
-	c.fn(tSet)
+	if len(errs) > 0 {
+		return nil, ErrParseAggregate{Errs: errs}
+	}
 
-	return nil
+	return tSet, nil
 }
 
-func (c *Cmd) help(pp parentParser) string {
-	numCols, _ := getTermNumCols()
-
+// usage returns the usage line of c, e.g. Usage: app foo <name> [FLAGS].
+// Options and flags are referenced by the sections of the help message
+// listing them, which are OPTIONS, [OPTIONS] and [FLAGS] for the ones
+// without a group and the name of each group in upper case for the
+// others, e.g. [OUTPUT]. A group with required options isn't bracketed.
+func (c *Cmd) usage(pp parentParser) string {
 	sb := strings.Builder{}
 
-	ppDescription := getParentParserDescription(pp)
-	if ppDescription != "" {
-		sb.WriteString(ppDescription + "\n\n")
-	}
-
 	sb.WriteString(fmt.Sprintf("Usage: %v", strings.Join(pp.cmds, " ")))
 
-	hasArgs := c.argsByPos != nil && len(c.argsByPos) > 0
-	hasRequiredOptions := c.requiredOptions != nil && len(c.requiredOptions) > 0
-	hasOptionalOptions := c.options != nil && len(c.options) > 0 && len(c.requiredOptions) != len(c.options)
-	hasFlags := c.flags != nil && len(c.flags) > 0
-
-	if hasArgs {
-		for _, arg := range c.argsByPos {
-			helpName := "<" + arg.Name + ">"
+	for _, arg := range c.argsByPos {
+		sb.WriteString(" <" + arg.Name + ">")
+	}
 
-			sb.WriteString(" " + helpName)
+	var requiredOptions, optionalOptions, flags bool
+	requiredGroups := make(map[string]bool)
+
+	for _, opt := range c.options {
+		switch {
+		case opt.Group != "":
+			requiredGroups[opt.Group] = requiredGroups[opt.Group] || opt.Required
+		case opt.Required:
+			requiredOptions = true
+		default:
+			optionalOptions = true
 		}
 	}
 
-	if hasRequiredOptions {
+	for _, f := range c.flags {
+		flags = flags || f.Group == ""
+	}
+
+	if requiredOptions {
 		sb.WriteString(" OPTIONS")
 	}
 
-	if hasOptionalOptions {
+	if optionalOptions {
 		sb.WriteString(" [OPTIONS]")
 	}
 
-	if hasFlags {
+	if flags {
 		sb.WriteString(" [FLAGS]")
 	}
 
-	sb.WriteRune('\n')
+	for _, group := range c.orderedGroups(pp.alphabetical) {
+		name := strings.ToUpper(strings.Join(strings.Fields(group), "-"))
 
-	// Arguments
-	if hasArgs {
-		biggestArgHelpNameLen := findBiggestArgHelpNameLen(c.argsByName)
-		sb.WriteRune('\n')
-
-		for _, arg := range c.argsByPos {
-			argNameStyled, argNameUnstyled := buildArgumentHelpName(arg.Name)
-			sb.WriteString(argNameStyled)
-
-			if arg.Description != "" {
-				descripFormatted := breakStringIntoPaddedLines(
-					numSpacesHelpNameAndDescription+biggestArgHelpNameLen,
-					' ',
-					numCols,
-					arg.Description,
-				)
-
-				// the new slice was created so that the help name could
-				// align with the description.
-				sb.Write([]byte(descripFormatted[len(argNameUnstyled):]))
-			}
-
-			sb.WriteRune('\n')
+		if requiredGroups[group] {
+			sb.WriteString(" " + name)
+		} else {
+			sb.WriteString(" [" + name + "]")
 		}
 	}
 
+	return sb.String()
+}
+
+// helpData returns the data of c's help message.
+func (c *Cmd) helpData(pp parentParser) HelpData {
+	data := HelpData{
+		Path:            append([]string(nil), pp.cmds...),
+		Description:     getParentParserDescription(pp),
+		LongDescription: c.longDescription,
+		Usage:           c.usage(pp),
+		Examples:        c.examples,
+		SeeAlso:         c.seeAlso,
+		Footer:          c.footer,
+	}
+
+	for _, arg := range c.argsByPos {
+		argNameStyled, argNameUnstyled := buildArgumentHelpName(arg.Name)
+
+		data.Args = append(data.Args, HelpTerm{
+			Name:        argNameUnstyled,
+			StyledName:  argNameStyled,
+			Description: arg.Description,
+		})
+	}
+
+	for _, option := range c.orderedOptions(pp.alphabetical) {
+		helpNameStyled, helpNameUnstyled := buildOptionHelpName(option)
+
+		data.Options = append(data.Options, HelpTerm{
+			Name:        helpNameUnstyled,
+			StyledName:  helpNameStyled,
+			Description: option.Description,
+			Required:    option.Required,
+			Group:       option.Group,
+		})
+	}
+
+	for _, flag := range c.orderedFlags(pp.alphabetical) {
+		helpNameStyled, helpNameUnstyled := buildOptionOrFlagHelpName(flag.Name, flag.Alias)
+
+		data.Flags = append(data.Flags, HelpTerm{
+			Name:        helpNameUnstyled,
+			StyledName:  helpNameStyled,
+			Description: flag.Description,
+			Group:       flag.Group,
+		})
+	}
+
+	// Arguments
+	if len(data.Args) > 0 {
+		data.Sections = append(data.Sections, HelpSection{
+			Terms:     data.Args,
+			NameWidth: findBiggestArgHelpNameLen(c.argsByName),
+		})
+	}
+
 	// Biggest option or flag's name length.
 	biggestOptionOrFlagHelpNameLen := findBiggestOptionOrFlagHelpNameLen(c.options, c.flags)
 
-	// Required options
-	if hasRequiredOptions {
-		sb.WriteRune('\n')
-		sb.WriteString("OPTIONS is one or more of:\n")
-
-		for _, option := range c.requiredOptions {
-			helpNameStyled, helpNameUnstyled := buildOptionOrFlagHelpName(option.Name, option.Alias)
-			sb.WriteString(helpIndentationSpaces + helpNameStyled)
-
-			if option.Description != "" {
-				descripFormatted := breakStringIntoPaddedLines(
-					helpIndentationNumSpaces+
-						numSpacesHelpNameAndDescription+
-						biggestOptionOrFlagHelpNameLen,
-					' ',
-					numCols,
-					option.Description,
-				)
-
-				sb.Write([]byte(descripFormatted[len(helpNameUnstyled)+helpIndentationNumSpaces:]))
+	// addSection adds a section with the options for which
+	// includeOption returns true followed by the flags for which
+	// includeFlag returns true, as long as there are any.
+	addSection := func(title string, includeOption, includeFlag func(t HelpTerm) bool) {
+		section := HelpSection{
+			Title:     title,
+			Indent:    helpIndentationNumSpaces,
+			NameWidth: biggestOptionOrFlagHelpNameLen,
+		}
+
+		for _, t := range data.Options {
+			if !includeOption(t) {
+				continue
 			}
 
-			sb.WriteRune('\n')
+			// Required options without a group are told apart by
+			// their section, but the ones in a group are marked.
+			if t.Required && t.Group != "" {
+				t.Description = strings.TrimSpace(t.Description + " (required)")
+			}
+
+			section.Terms = append(section.Terms, t)
+		}
+
+		for _, t := range data.Flags {
+			if includeFlag(t) {
+				section.Terms = append(section.Terms, t)
+			}
+		}
+
+		if len(section.Terms) > 0 {
+			data.Sections = append(data.Sections, section)
 		}
 	}
 
-	// Optional options
-	if hasOptionalOptions {
-		sb.WriteRune('\n')
-		sb.WriteString("[OPTIONS] is one or more of:\n")
+	none := func(t HelpTerm) bool {
+		return false
+	}
 
-		for _, option := range c.options {
-			if option.Required {
-				continue
-			}
+	addSection("OPTIONS is one or more of:", func(t HelpTerm) bool {
+		return t.Group == "" && t.Required
+	}, none)
+	addSection("[OPTIONS] is one or more of:", func(t HelpTerm) bool {
+		return t.Group == "" && !t.Required
+	}, none)
+	addSection("[FLAGS] is one or more of:", none, func(t HelpTerm) bool {
+		return t.Group == ""
+	})
+
+	// Groups, each one with its options followed by its flags.
+	for _, group := range c.orderedGroups(pp.alphabetical) {
+		inGroup := func(t HelpTerm) bool {
+			return t.Group == group
+		}
 
-			helpNameStyled, helpNameUnstyled := buildOptionOrFlagHelpName(option.Name, option.Alias)
-			sb.WriteString(helpIndentationSpaces + helpNameStyled)
+		addSection(group+":", inGroup, inGroup)
+	}
 
-			if option.Description != "" {
-				descripFormatted := breakStringIntoPaddedLines(
-					helpIndentationNumSpaces+numSpacesHelpNameAndDescription+biggestOptionOrFlagHelpNameLen,
-					' ',
-					numCols,
-					option.Description,
-				)
+	// Built-in subcmds, which only a root cmd without arguments has.
+	if pp.builtins != nil {
+		section := HelpSection{Title: "Built-in subcmds:", Indent: helpIndentationNumSpaces}
 
-				sb.Write([]byte(descripFormatted[len(helpNameUnstyled)+helpIndentationNumSpaces:]))
+		for _, item := range pp.builtins.orderedItems(pp.alphabetical) {
+			if stringWidth(item.Name) > section.NameWidth {
+				section.NameWidth = stringWidth(item.Name)
 			}
 
-			sb.WriteRune('\n')
+			section.Terms = append(section.Terms, HelpTerm{
+				Name:        item.Name,
+				StyledName:  customo.Format(item.Name, customo.AttrBold),
+				Description: item.Description,
+			})
 		}
-	}
 
-	// Flags
-	if hasFlags {
-		sb.WriteRune('\n')
-		sb.WriteString("[FLAGS] is one or more of:\n")
+		data.Subcmds = append(data.Subcmds, section.Terms...)
+		data.Sections = append(data.Sections, section)
+	}
 
-		for _, flag := range c.flags {
-			helpNameStyled, helpNameUnstyled := buildOptionOrFlagHelpName(flag.Name, flag.Alias)
-			sb.WriteString(helpIndentationSpaces + helpNameStyled)
+	// Global options
+	if section, ok := colorOptionHelpSection(pp, c, biggestOptionOrFlagHelpNameLen); ok {
+		data.Options = append(data.Options, section.Terms...)
+		data.Sections = append(data.Sections, section)
+	}
 
-			if flag.Description != "" {
-				descripFormatted := breakStringIntoPaddedLines(
-					helpIndentationNumSpaces+numSpacesHelpNameAndDescription+biggestOptionOrFlagHelpNameLen,
-					' ',
-					numCols,
-					flag.Description,
-				)
+	// Examples
+	if len(c.examples) > 0 {
+		section := HelpSection{Title: "Examples:", Indent: helpIndentationNumSpaces}
 
-				sb.Write([]byte(descripFormatted[len(helpNameUnstyled)+helpIndentationNumSpaces:]))
+		for _, example := range c.examples {
+			if stringWidth(example.Cmd) > section.NameWidth {
+				section.NameWidth = stringWidth(example.Cmd)
 			}
 
-			sb.WriteRune('\n')
+			section.Terms = append(section.Terms, HelpTerm{
+				Name:        example.Cmd,
+				StyledName:  customo.Format(example.Cmd, customo.AttrBold),
+				Description: example.Description,
+			})
 		}
+
+		data.Sections = append(data.Sections, section)
 	}
 
-	return sb.String()
+	// See also
+	if len(c.seeAlso) > 0 {
+		section := HelpSection{Title: "See also:", Indent: helpIndentationNumSpaces}
+
+		for _, path := range c.seeAlso {
+			section.Terms = append(section.Terms, HelpTerm{Name: path, StyledName: path})
+		}
+
+		data.Sections = append(data.Sections, section)
+	}
+
+	return data
 }
@@ -6,9 +6,10 @@ import (
 	"strings"
 )
 
-var optionWithValueRegExp = regexp.MustCompile("^--?([^-]{1}[^=\\s]*)=([^\\s=]+)?$")
+var optionWithValueRegExp = regexp.MustCompile("^--?([^-]{1}[^=\\s]*)=(\\S+)?$")
 var optionWithoutValueRegExp = regexp.MustCompile("^--?([^-]{1}[^=\\s]*)$")
-var optionWithOrWithoutValueRegExp = regexp.MustCompile("^--?([^-]{1}[^=\\s]*)(?:=([^\\s=]+)?)?$")
+var optionWithOrWithoutValueRegExp = regexp.MustCompile("^--?([^-]{1}[^=\\s]*)(?:=(\\S+)?)?$")
+var negativeNumberRegExp = regexp.MustCompile("^-(?:\\d+\\.?\\d*|\\.\\d+)(?:[eE][-+]?\\d+)?$")
 
 // isValueValidForTermType returns whether value is valid for a given t.
 func isValueValidForTermType(t TermType, value string) (interface{}, bool) {
@@ -44,6 +45,11 @@ func isOptionWithoutValue(str string) bool {
 	return optionWithoutValueRegExp.MatchString(str)
 }
 
+// isNegativeNumber returns whether str is a negative number, e.g. -5 or -2.5.
+func isNegativeNumber(str string) bool {
+	return negativeNumberRegExp.MatchString(str)
+}
+
 // extractOptionName extracts the name from an option, e.g. year out of --year=1990.
 func extractOptionName(str string) (name string, isAlias bool) {
 	matches := optionWithOrWithoutValueRegExp.FindStringSubmatch(str)
@@ -67,3 +73,16 @@ func extractOptionValue(str string) string {
 
 	return matches[2]
 }
+
+// splitKeyValuePair splits str into a key and a value, e.g. env and prod
+// out of env=prod. Only the first = is considered, so the value can
+// contain = characters. If str doesn't contain a = or the key is empty,
+// ok is false.
+func splitKeyValuePair(str string) (key, value string, ok bool) {
+	i := strings.Index(str, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return str[:i], str[i+1:], true
+}
@@ -67,6 +67,7 @@ func TestIsOptionWithValue(t *testing.T) {
 		{"--opt=300", true},
 		{"--opt=", true},
 		{"--opt", false},
+		{"--opt=k=v", true},
 	}
 
 	for i, test := range tests {
@@ -136,6 +137,7 @@ func TestExtractOptionValue(t *testing.T) {
 		{"--opt=300", "300"},
 		{"--opt=", ""},
 		{"--opt", ""},
+		{"--opt=k=v", "k=v"},
 	}
 
 	for i, test := range tests {
@@ -148,3 +150,54 @@ func TestExtractOptionValue(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitKeyValuePair(t *testing.T) {
+	tests := []struct {
+		str   string
+		key   string
+		value string
+		ok    bool
+	}{
+		{"env=prod", "env", "prod", true},
+		{"env=", "env", "", true},
+		{"url=a=b", "url", "a=b", true},
+		{"=prod", "", "", false},
+		{"env", "", "", false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			key, value, ok := splitKeyValuePair(test.str)
+
+			if key != test.key || value != test.value || ok != test.ok {
+				t.Errorf("got (%v, %v, %v), want (%v, %v, %v)", key, value, ok, test.key, test.value, test.ok)
+			}
+		})
+	}
+}
+
+func TestIsNegativeNumber(t *testing.T) {
+	tests := []struct {
+		str string
+		res bool
+	}{
+		{"-5", true},
+		{"-2.5", true},
+		{"-.5", true},
+		{"-1e3", true},
+		{"5", false},
+		{"--5", false},
+		{"-v", false},
+		{"-5a", false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := isNegativeNumber(test.str)
+
+			if res != test.res {
+				t.Errorf("got %v, want %v", res, test.res)
+			}
+		})
+	}
+}
@@ -1,33 +1,39 @@
 package cfop
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestCmd(t *testing.T) {
 	tests := []struct {
-		config     CmdConfig
-		strs       []string
-		err        error
-		intOpts    map[string]int
-		floatOpts  map[string]float64
-		stringOpts map[string]string
-		intArgs    map[string]int
-		floatArgs  map[string]float64
-		stringArgs map[string]string
-		flags      map[string]bool
+		config      CmdConfig
+		strs        []string
+		err         error
+		intOpts     map[string]int
+		floatOpts   map[string]float64
+		stringOpts  map[string]string
+		mapOpts     map[string]map[string]interface{}
+		intArgs     map[string]int
+		floatArgs   map[string]float64
+		stringArgs  map[string]string
+		flags       map[string]bool
+		passthrough []string
 	}{
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
-					{"salary", "sl", "", TermFloat, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
+					{Name: "salary", Alias: "sl", T: TermFloat, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 				Args: []CmdArg{
 					{"first", "", TermString},
@@ -52,7 +58,7 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"title", "t", "", TermString, false},
+					{Name: "title", Alias: "t", T: TermString},
 				},
 				Args: []CmdArg{
 					{"first", "", TermFloat},
@@ -68,7 +74,7 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"title", "t", "", TermString, false},
+					{Name: "title", Alias: "t", T: TermString},
 				},
 				Args: []CmdArg{
 					{"first", "", TermInt},
@@ -85,7 +91,7 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"title", "t", "", TermString, false},
+					{Name: "title", Alias: "t", T: TermString},
 				},
 				Args: []CmdArg{
 					{"first", "", TermInt},
@@ -101,11 +107,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 				Args: []CmdArg{
 					{"first", "", TermString},
@@ -120,11 +126,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 				Args: []CmdArg{
 					{"first", "", TermString},
@@ -139,11 +145,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 			},
 			strs: []string{"-n", "John", "--year"},
@@ -154,11 +160,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 			},
 			strs: []string{"-n", "John", "--year="},
@@ -169,11 +175,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 				Args: []CmdArg{
 					{"first", "", TermString},
@@ -185,11 +191,11 @@ func TestCmd(t *testing.T) {
 		{
 			config: CmdConfig{
 				Options: []CmdOption{
-					{"name", "n", "", TermString, false},
-					{"year", "y", "", TermInt, true},
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "year", Alias: "y", T: TermInt, Required: true},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 			},
 			strs: []string{"-n", "John", "--year=1990", "foobar"},
@@ -202,11 +208,32 @@ func TestCmd(t *testing.T) {
 					{"Second", "", TermInt},
 				},
 				Flags: []CmdFlag{
-					{"line", "l", ""},
+					{Name: "line", Alias: "l"},
 				},
 			},
 			strs: []string{"-l"},
-			err:  ErrMissingArguments,
+			err: ErrMissingArguments{
+				Args: []MissingArgument{
+					{Name: "first", Pos: 0, T: TermFloat},
+					{Name: "Second", Pos: 1, T: TermInt},
+				},
+				Usage: "Usage: testing <first> <Second> [FLAGS]",
+			},
+		},
+		{
+			config: CmdConfig{
+				Args: []CmdArg{
+					{"first", "", TermFloat},
+					{"Second", "", TermInt},
+				},
+			},
+			strs: []string{"20.5"},
+			err: ErrMissingArguments{
+				Args: []MissingArgument{
+					{Name: "Second", Pos: 1, T: TermInt},
+				},
+				Usage: "Usage: testing <first> <Second>",
+			},
 		},
 		{
 			config: CmdConfig{
@@ -214,7 +241,7 @@ func TestCmd(t *testing.T) {
 					{"first", "", TermInt},
 				},
 				Options: []CmdOption{
-					{"name", "n", "", TermString, true},
+					{Name: "name", Alias: "n", T: TermString, Required: true},
 				},
 			},
 			strs: []string{"20"},
@@ -225,6 +252,280 @@ func TestCmd(t *testing.T) {
 			strs:   []string{"--name", "John"},
 			err:    ErrUnexpectedOptionOrFlag{OptionOrFlagName: "name"},
 		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "name", Alias: "n", T: TermString},
+					{Name: "number", T: TermInt},
+				},
+				Flags: []CmdFlag{
+					{Name: "verbose", Alias: "v"},
+				},
+				AllowAbbreviations: true,
+			},
+			strs: []string{"--na", "John", "--numb=20", "--verb"},
+			err:  nil,
+			stringOpts: map[string]string{
+				"name": "John",
+			},
+			intOpts: map[string]int{
+				"number": 20,
+			},
+			flags: map[string]bool{
+				"verbose": true,
+			},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "name", T: TermString},
+				},
+				Flags: []CmdFlag{
+					{Name: "names"},
+				},
+				AllowAbbreviations: true,
+			},
+			strs: []string{"--name", "John", "--names"},
+			err:  nil,
+			stringOpts: map[string]string{
+				"name": "John",
+			},
+			flags: map[string]bool{
+				"names": true,
+			},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "user", Alias: "u", T: TermString},
+				},
+				Flags: []CmdFlag{
+					{Name: "tty", Alias: "t"},
+				},
+				Args: []CmdArg{
+					{"cmd", "", TermString},
+				},
+				StrictOrder: true,
+			},
+			strs: []string{"-t", "-u", "root", "ls", "-l", "--help", "-u=foo"},
+			err:  nil,
+			stringOpts: map[string]string{
+				"user": "root",
+			},
+			flags: map[string]bool{
+				"tty": true,
+			},
+			stringArgs:  map[string]string{"cmd": "ls"},
+			passthrough: []string{"-l", "--help", "-u=foo"},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "tty", Alias: "t"},
+				},
+				StrictOrder: true,
+			},
+			strs:        []string{"ls", "-t"},
+			err:         nil,
+			flags:       map[string]bool{"tty": false},
+			passthrough: []string{"ls", "-t"},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "tty", Alias: "t"},
+				},
+				StrictOrder: true,
+			},
+			strs:        []string{"--", "-t", "--"},
+			err:         nil,
+			flags:       map[string]bool{"tty": false},
+			passthrough: []string{"-t", "--"},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "line", Alias: "l"},
+				},
+				Args: []CmdArg{
+					{"first", "", TermString},
+					{"second", "", TermInt},
+				},
+				NoNegativeNumbers: true,
+			},
+			strs:       []string{"-l", "--", "--help", "-5"},
+			err:        nil,
+			flags:      map[string]bool{"line": true},
+			stringArgs: map[string]string{"first": "--help"},
+			intArgs:    map[string]int{"second": -5},
+		},
+		{
+			config: CmdConfig{
+				Args: []CmdArg{
+					{"first", "", TermString},
+					{"second", "", TermInt},
+				},
+				StrictOrder: true,
+			},
+			strs: []string{"foo", "-v"},
+			err: ErrArgumentExpectsDifferentValueType{
+				ArgumentPos:  1,
+				ArgumentName: "second",
+				ExpectedType: TermInt,
+				Value:        "-v",
+			},
+		},
+		{
+			config: CmdConfig{
+				Args: []CmdArg{
+					{"first", "", TermString},
+				},
+			},
+			strs: []string{"foo", "bar"},
+			err:  ErrUnexpectedArgument{Argument: "bar"},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "number", T: TermInt},
+				},
+				Flags: []CmdFlag{
+					{Name: "null"},
+				},
+				AllowAbbreviations: true,
+			},
+			strs: []string{"--nu"},
+			err: ErrAmbiguousOptionOrFlag{
+				OptionOrFlagName: "nu",
+				Candidates:       []string{"null", "number"},
+			},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "verbose", Alias: "v"},
+				},
+			},
+			strs: []string{"--verb"},
+			err:  ErrUnexpectedOptionOrFlag{OptionOrFlagName: "verb"},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "offset", Alias: "o", T: TermInt},
+					{Name: "ratio", T: TermFloat},
+				},
+				Args: []CmdArg{
+					{"steps", "", TermInt},
+					{"scale", "", TermFloat},
+				},
+			},
+			strs: []string{"-5", "--offset", "-3", "--ratio", "-.5", "-2.5e2"},
+			err:  nil,
+			intOpts: map[string]int{
+				"offset": -3,
+			},
+			floatOpts: map[string]float64{
+				"ratio": -0.5,
+			},
+			intArgs:   map[string]int{"steps": -5},
+			floatArgs: map[string]float64{"scale": -250},
+		},
+		{
+			config: CmdConfig{
+				Flags: []CmdFlag{
+					{Name: "five", Alias: "5"},
+				},
+				Args: []CmdArg{
+					{"steps", "", TermInt},
+				},
+			},
+			strs: []string{"-5", "-2"},
+			err:  nil,
+			flags: map[string]bool{
+				"five": true,
+			},
+			intArgs: map[string]int{"steps": -2},
+		},
+		{
+			config: CmdConfig{
+				Args: []CmdArg{
+					{"name", "", TermString},
+				},
+			},
+			strs: []string{"-5"},
+			err:  ErrUnexpectedOptionOrFlag{OptionOrFlagName: "5", IsAlias: true},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "offset", T: TermInt},
+				},
+				NoNegativeNumbers: true,
+			},
+			strs: []string{"--offset", "-3"},
+			err:  ErrOptionsExpectsAValue{OptionName: "offset"},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "label", Alias: "l", T: TermString, Map: true},
+					{Name: "limit", T: TermInt, Map: true},
+				},
+			},
+			strs: []string{"--label", "env=prod", "-l=tier=web=1", "--limit", "cpu=2", "--label", "env=dev"},
+			err:  nil,
+			mapOpts: map[string]map[string]interface{}{
+				"label": {"env": "dev", "tier": "web=1"},
+				"limit": {"cpu": 2},
+			},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "label", T: TermString, Map: true, DuplicateKeys: DuplicateKeyKeepFirst},
+				},
+			},
+			strs: []string{"--label", "env=prod", "--label", "env=dev"},
+			err:  nil,
+			mapOpts: map[string]map[string]interface{}{
+				"label": {"env": "prod"},
+			},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "label", Alias: "l", T: TermString, Map: true, DuplicateKeys: DuplicateKeyError},
+				},
+			},
+			strs: []string{"--label", "env=prod", "-l", "env=dev"},
+			err: ErrDuplicateMapOptionKey{
+				OptionName: "l",
+				IsAlias:    true,
+				Key:        "env",
+			},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "label", T: TermString, Map: true},
+				},
+			},
+			strs: []string{"--label", "=prod"},
+			err:  ErrOptionExpectsKeyValuePair{OptionName: "label"},
+		},
+		{
+			config: CmdConfig{
+				Options: []CmdOption{
+					{Name: "limit", T: TermInt, Map: true},
+				},
+			},
+			strs: []string{"--limit=cpu=two"},
+			err: ErrOptionExpectsDifferentValueType{
+				OptionName:   "limit",
+				ExpectedType: TermInt,
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -245,7 +546,16 @@ func TestCmd(t *testing.T) {
 				cmds: []string{"testing"},
 			}, test.strs)
 
-			if err != test.err {
+			if err != nil {
+				var errParse ErrParse
+				if !errors.As(err, &errParse) {
+					t.Fatalf("got %T, want ErrParse", err)
+				}
+
+				err = errParse.Err
+			}
+
+			if !reflect.DeepEqual(err, test.err) {
 				t.Fatalf("got %v, want %v", err, test.err)
 			}
 
@@ -293,6 +603,16 @@ func TestCmd(t *testing.T) {
 				}
 			}
 
+			if test.mapOpts != nil {
+				for name, expectedRes := range test.mapOpts {
+					res := set.GetOptMap(name)
+
+					if !reflect.DeepEqual(res, expectedRes) {
+						t.Fatalf("got %v, want %v", res, expectedRes)
+					}
+				}
+			}
+
 			if test.intArgs != nil {
 				for name, expectedRes := range test.intArgs {
 					res := set.GetArgInt(name)
@@ -323,6 +643,10 @@ func TestCmd(t *testing.T) {
 				}
 			}
 
+			if !reflect.DeepEqual(set.GetPassthrough(), test.passthrough) {
+				t.Fatalf("got %v, want %v", set.GetPassthrough(), test.passthrough)
+			}
+
 			if test.flags != nil {
 				for name, expectedRes := range test.flags {
 					res := set.GetFlag(name)
@@ -335,3 +659,250 @@ func TestCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestNewCmdDuplicateKeysWithoutMap(t *testing.T) {
+	defer func() {
+		want := ErrDuplicateKeysWithoutMap{Option: "name"}
+
+		if r := recover(); r != want {
+			t.Errorf("got %v, want %v", r, want)
+		}
+	}()
+
+	NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "name", T: TermString, DuplicateKeys: DuplicateKeyError},
+		},
+	})
+}
+
+func TestCmdErrParse(t *testing.T) {
+	tests := []struct {
+		strs  []string
+		index int
+		term  string
+	}{
+		{[]string{"--year", "foo"}, 3, "foo"},
+		{[]string{"--year=foo"}, 2, "--year=foo"},
+		{[]string{"foo", "--what"}, 3, "--what"},
+		{[]string{"--year", "20", "foo", "bar"}, 5, "bar"},
+		{[]string{"--year", "20"}, -1, ""},
+	}
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "year", T: TermInt},
+		},
+		Args: []CmdArg{
+			{Name: "first", T: TermString},
+		},
+	})
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := cmd.Parse(parentParser{
+				parser: &rootCmd{
+					name: "testing",
+				},
+				cmds: []string{"testing", "foo"},
+			}, test.strs)
+
+			var errParse ErrParse
+			if !errors.As(err, &errParse) {
+				t.Fatalf("got %v, want ErrParse", err)
+			}
+
+			if errParse.Index != test.index || errParse.Term != test.term {
+				t.Errorf("got (%v, %v), want (%v, %v)", errParse.Index, errParse.Term, test.index, test.term)
+			}
+
+			if !reflect.DeepEqual(errParse.Cmds, []string{"testing", "foo"}) {
+				t.Errorf("got %v, want %v", errParse.Cmds, []string{"testing", "foo"})
+			}
+		})
+	}
+}
+
+func TestCmdCollectErrors(t *testing.T) {
+	called := false
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {
+			called = true
+		},
+		Options: []CmdOption{
+			{Name: "year", Alias: "y", T: TermInt},
+			{Name: "name", T: TermString, Required: true},
+		},
+		Args: []CmdArg{
+			{Name: "first", T: TermInt},
+			{Name: "second", T: TermString},
+			{Name: "third", T: TermString},
+		},
+		CollectErrors: true,
+	})
+
+	err := cmd.Parse(parentParser{
+		parser: &rootCmd{
+			name: "testing",
+		},
+		cmds: []string{"testing"},
+	}, []string{"--what", "-y", "foo", "bar", "--year="})
+
+	var errAggregate ErrParseAggregate
+	if !errors.As(err, &errAggregate) {
+		t.Fatalf("got %v, want ErrParseAggregate", err)
+	}
+
+	if called {
+		t.Error("cmd's function was called")
+	}
+
+	usage := "Usage: testing <first> <second> <third> OPTIONS [OPTIONS]"
+	expectedErrs := []ErrParse{
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: 1,
+			Term:  "--what",
+			Err:   ErrUnexpectedOptionOrFlag{OptionOrFlagName: "what"},
+		},
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: 3,
+			Term:  "foo",
+			Err: ErrOptionExpectsDifferentValueType{
+				OptionName:   "y",
+				IsAlias:      true,
+				ExpectedType: TermInt,
+			},
+		},
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: 4,
+			Term:  "bar",
+			Err: ErrArgumentExpectsDifferentValueType{
+				ArgumentPos:  0,
+				ArgumentName: "first",
+				ExpectedType: TermInt,
+				Value:        "bar",
+			},
+		},
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: 5,
+			Term:  "--year=",
+			Err:   ErrOptionsExpectsAValue{OptionName: "year"},
+		},
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: -1,
+			Err: ErrMissingArguments{
+				Args: []MissingArgument{
+					{Name: "second", Pos: 1, T: TermString},
+					{Name: "third", Pos: 2, T: TermString},
+				},
+				Usage: usage,
+			},
+		},
+		{
+			Cmds:  []string{"testing"},
+			Usage: usage,
+			Index: -1,
+			Err:   ErrRequiredOptionNotProvided{OptionName: "name"},
+		},
+	}
+
+	if !reflect.DeepEqual(errAggregate.Errs, expectedErrs) {
+		t.Errorf("got %#v, want %#v", errAggregate.Errs, expectedErrs)
+	}
+
+	var errRequired ErrRequiredOptionNotProvided
+	if !errors.As(err, &errRequired) || errRequired.OptionName != "name" {
+		t.Errorf("got %v, want ErrRequiredOptionNotProvided to be found", err)
+	}
+
+	var errParse ErrParse
+	if !errors.As(err, &errParse) || !reflect.DeepEqual(errParse, expectedErrs[0]) {
+		t.Errorf("got %#v, want %#v", errParse, expectedErrs[0])
+	}
+
+	// The usage line isn't part of the message of the missing arguments.
+	msg := strings.Join([]string{
+		"unexpected --what option/flag",
+		"-y option expects a value of type int",
+		"the <first> argument (bar) expects a value of type int",
+		"--year option expects a value",
+		"missing argument(s): #2 <second> (string), #3 <third> (string)",
+		"--name option is required",
+	}, "\n")
+
+	if err.Error() != msg {
+		t.Errorf("got %q, want %q", err.Error(), msg)
+	}
+}
+
+func TestCmdCollectErrorsRequiredOptions(t *testing.T) {
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "n", T: TermInt, Required: true},
+		},
+		CollectErrors: true,
+	})
+
+	// An option provided with an invalid value isn't reported as missing.
+	tests := []struct {
+		strs []string
+		err  error
+	}{
+		{[]string{"--n", "q"}, ErrOptionExpectsDifferentValueType{OptionName: "n", ExpectedType: TermInt}},
+		{[]string{"--n=q"}, ErrOptionExpectsDifferentValueType{OptionName: "n", ExpectedType: TermInt}},
+		{[]string{"--n="}, ErrOptionsExpectsAValue{OptionName: "n"}},
+		{[]string{"--n"}, ErrOptionsExpectsAValue{OptionName: "n"}},
+		{[]string{}, ErrRequiredOptionNotProvided{OptionName: "n"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := cmd.Parse(parentParser{
+				parser: &rootCmd{
+					name: "testing",
+				},
+				cmds: []string{"testing"},
+			}, test.strs)
+
+			var errAggregate ErrParseAggregate
+			if !errors.As(err, &errAggregate) {
+				t.Fatalf("got %v, want ErrParseAggregate", err)
+			}
+
+			if len(errAggregate.Errs) != 1 || errAggregate.Errs[0].Err != test.err {
+				t.Errorf("got %v, want %v", errAggregate.Errs, test.err)
+			}
+		})
+	}
+}
+
+func TestErrParseAggregateIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ErrParseAggregate{
+		Errs: []ErrParse{
+			{Err: ErrUnexpectedOptionOrFlag{OptionOrFlagName: "foo"}},
+			{Err: ErrMissingSubcmd},
+		},
+	})
+
+	if !errors.Is(err, ErrMissingSubcmd) {
+		t.Errorf("got %v, want ErrMissingSubcmd to be found", err)
+	}
+
+	if errors.Is(err, ErrUnknownSubcmd{SubcmdName: "foo"}) {
+		t.Errorf("got %v, want ErrUnknownSubcmd not to be found", err)
+	}
+}
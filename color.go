@@ -0,0 +1,173 @@
+package cfop
+
+import (
+	"io"
+	"os"
+
+	"github.com/efreitasn/customo"
+	"golang.org/x/sys/unix"
+)
+
+// ColorMode controls whether the output is styled, e.g. with bold text.
+type ColorMode string
+
+// Color modes.
+const (
+	// ColorAuto styles the output only if it's written to a terminal.
+	// Styling is disabled if the NO_COLOR environment variable is set,
+	// or forced if the CLICOLOR_FORCE one is set to something other
+	// than 0.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways always styles the output.
+	ColorAlways ColorMode = "always"
+	// ColorNever never styles the output.
+	ColorNever ColorMode = "never"
+)
+
+// getenv reads an environment variable. It's a variable so that it can
+// be replaced when testing.
+var getenv = os.Getenv
+
+// isValidColorMode returns whether mode is one of the color modes.
+func isValidColorMode(mode ColorMode) bool {
+	return mode == ColorAuto || mode == ColorAlways || mode == ColorNever
+}
+
+// useColor returns whether the output written to w is styled in mode.
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	if force := getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+
+	return isTerminal(w)
+}
+
+// isTerminal returns whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+
+	_, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+
+	return err == nil
+}
+
+// isColorOption returns whether str is the built-in --color option, as
+// --color=MODE or --color MODE, which is the case only if it's enabled
+// in pp.
+func (pp parentParser) isColorOption(str string) bool {
+	if !pp.colorOption || pp.optionsEnded || (!isOptionWithValue(str) && !isOptionWithoutValue(str)) {
+		return false
+	}
+
+	name, isAlias := extractOptionName(str)
+
+	return name == "color" && !isAlias
+}
+
+// parseColorOption parses the built-in --color option at strs[i]. It
+// returns the mode provided and the index of the last term taken, which
+// is strs[i+1] if the mode is provided as --color MODE. If there's an
+// error, the index returned is the one of the term that caused it.
+func parseColorOption(strs []string, i int) (ColorMode, int, error) {
+	end := i
+	value := extractOptionValue(strs[i])
+
+	if isOptionWithoutValue(strs[i]) {
+		if i+1 >= len(strs) {
+			return "", i, ErrOptionsExpectsAValue{OptionName: "color"}
+		}
+
+		end = i + 1
+		value = strs[end]
+	}
+
+	if value == "" {
+		return "", i, ErrOptionsExpectsAValue{OptionName: "color"}
+	}
+
+	mode := ColorMode(value)
+	if !isValidColorMode(mode) {
+		return "", end, ErrInvalidColorMode{Mode: mode}
+	}
+
+	return mode, end, nil
+}
+
+// helpColorMode returns the color mode of a help message requested with
+// strs[:i] already parsed. Since parsing stops at the help flag, the
+// built-in --color option is looked for in strs[i:], ignoring terms
+// referencing an option or flag of p and every term after --. If
+// there's an error, it's returned along with the index of the term that
+// caused it.
+func helpColorMode(pp parentParser, p Parser, strs []string, i int) (ColorMode, int, error) {
+	mode := pp.colorMode
+
+	for ; i < len(strs) && strs[i] != "--"; i++ {
+		if !pp.isColorOption(strs[i]) || hasColorOptionOrFlag(p) {
+			continue
+		}
+
+		var err error
+
+		mode, i, err = parseColorOption(strs, i)
+		if err != nil {
+			return "", i, err
+		}
+	}
+
+	return mode, -1, nil
+}
+
+// hasColorOptionOrFlag returns whether p is a cmd with an option or a
+// flag named color, which takes the place of the built-in --color
+// option. Abbreviations aren't considered, since --color is only an
+// abbreviation if there isn't an option or flag with its exact name.
+func hasColorOptionOrFlag(p Parser) bool {
+	c, ok := p.(*Cmd)
+
+	return ok && (c.options["color"] != nil || c.flags["color"] != nil)
+}
+
+// colorOptionHelpSection returns the section of the help message of p
+// listing the built-in --color option, whose names are nameWidth columns
+// wide, so that they're aligned with the ones of the other sections. It's
+// only listed if it's enabled in pp and p doesn't have an option or flag
+// named color.
+func colorOptionHelpSection(pp parentParser, p Parser, nameWidth int) (HelpSection, bool) {
+	if !pp.colorOption || hasColorOptionOrFlag(p) {
+		return HelpSection{}, false
+	}
+
+	name := "--color"
+
+	if stringWidth(name) > nameWidth {
+		nameWidth = stringWidth(name)
+	}
+
+	return HelpSection{
+		Title: "Global options:",
+		Terms: []HelpTerm{
+			{
+				Name:        name,
+				StyledName:  customo.Format(name, customo.AttrBold),
+				Description: "whether the output is styled: auto, always or never",
+			},
+		},
+		Indent:    helpIndentationNumSpaces,
+		NameWidth: nameWidth,
+	}, true
+}
@@ -0,0 +1,346 @@
+package cfop
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUseColor(t *testing.T) {
+	defer func(originalGetenv func(string) string) {
+		getenv = originalGetenv
+	}(getenv)
+
+	tests := []struct {
+		mode ColorMode
+		env  map[string]string
+		res  bool
+	}{
+		{ColorAuto, nil, false},
+		{ColorAlways, nil, true},
+		{ColorNever, map[string]string{"CLICOLOR_FORCE": "1"}, false},
+		{ColorAuto, map[string]string{"CLICOLOR_FORCE": "1"}, true},
+		{ColorAuto, map[string]string{"CLICOLOR_FORCE": "0"}, false},
+		{ColorAuto, map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, false},
+		{ColorAlways, map[string]string{"NO_COLOR": "1"}, true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			getenv = func(key string) string {
+				return test.env[key]
+			}
+
+			res := useColor(test.mode, &bytes.Buffer{})
+
+			if res != test.res {
+				t.Errorf("got %v, want %v", res, test.res)
+			}
+		})
+	}
+}
+
+func TestParseColorOption(t *testing.T) {
+	tests := []struct {
+		strs []string
+		i    int
+		mode ColorMode
+		end  int
+		err  error
+	}{
+		{[]string{"--color=never", "foo"}, 0, ColorNever, 0, nil},
+		{[]string{"foo", "--color", "always", "--help"}, 1, ColorAlways, 2, nil},
+		{[]string{"foo", "--color"}, 1, "", 1, ErrOptionsExpectsAValue{OptionName: "color"}},
+		{[]string{"--color=", "foo"}, 0, "", 0, ErrOptionsExpectsAValue{OptionName: "color"}},
+		{[]string{"--color", "blue"}, 0, "", 1, ErrInvalidColorMode{Mode: "blue"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mode, end, err := parseColorOption(test.strs, test.i)
+
+			if err != test.err {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			if mode != test.mode {
+				t.Errorf("got %v, want %v", mode, test.mode)
+			}
+
+			if end != test.end {
+				t.Errorf("got %v, want %v", end, test.end)
+			}
+		})
+	}
+}
+
+func TestAppColor(t *testing.T) {
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Flags: []CmdFlag{
+			{Name: "verbose"},
+		},
+	})
+	set := NewSubcmdsSet(Subcmd{Name: "foo", Parser: cmd})
+
+	tests := []struct {
+		color       ColorMode
+		colorOption bool
+		strs        []string
+		bold        bool
+		err         error
+	}{
+		{"", false, []string{"foo", "--help"}, false, nil},
+		{ColorAlways, false, []string{"foo", "--help"}, true, nil},
+		{ColorAlways, true, []string{"foo", "--help", "--color=never"}, false, nil},
+		{ColorNever, true, []string{"--color", "always", "foo", "--help"}, true, nil},
+		{"", false, []string{"foo", "--help", "--color=always"}, false, nil},
+		{"", true, []string{"foo", "--color=blue"}, false, ErrInvalidColorMode{Mode: "blue"}},
+		{"blue", false, []string{"foo"}, false, ErrInvalidColorMode{Mode: "blue"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:        "testing",
+				Root:        set,
+				IO:          IOStreams{Out: &out},
+				Color:       test.color,
+				ColorOption: test.colorOption,
+			}
+
+			err := app.Run(test.strs)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("got %v, want %v", err, test.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if bold := strings.Contains(out.String(), "\x1b["); bold != test.bold {
+				t.Errorf("got %v, want %v in %q", bold, test.bold, out.String())
+			}
+		})
+	}
+}
+
+func TestAppColorOption(t *testing.T) {
+	var values []interface{}
+
+	set := NewSubcmdsSet(
+		Subcmd{
+			Name: "paint",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {
+					values = []interface{}{cts.GetOptString("color")}
+				},
+				Options: []CmdOption{
+					{Name: "color", T: TermString},
+				},
+			}),
+		},
+		Subcmd{
+			Name: "say",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {
+					values = []interface{}{cts.GetOptString("msg")}
+				},
+				Options: []CmdOption{
+					{Name: "msg", T: TermString},
+				},
+			}),
+		},
+		Subcmd{
+			Name: "exec",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {
+					values = []interface{}{cts.GetArgString("name"), cts.GetPassthrough()}
+				},
+				Args: []CmdArg{
+					{Name: "name", T: TermString},
+				},
+				StrictOrder: true,
+			}),
+		},
+		Subcmd{
+			Name: "mix",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {
+					values = []interface{}{cts.GetFlag("color-x")}
+				},
+				Flags: []CmdFlag{
+					{Name: "color-x"},
+				},
+				AllowAbbreviations: true,
+			}),
+		},
+	)
+
+	tests := []struct {
+		strs   []string
+		values []interface{}
+		err    error
+		index  int
+	}{
+		{[]string{"paint", "--color", "red"}, []interface{}{"red"}, nil, 0},
+		{[]string{"paint", "--color=red"}, []interface{}{"red"}, nil, 0},
+		{[]string{"--color", "never", "paint", "--color=red"}, []interface{}{"red"}, nil, 0},
+		{[]string{"say", "--msg", "hi", "--color=never"}, []interface{}{"hi"}, nil, 0},
+		{[]string{"say", "--color", "never", "--msg", "hi"}, []interface{}{"hi"}, nil, 0},
+		{[]string{"say", "--msg=--color=never"}, []interface{}{"--color=never"}, nil, 0},
+		{[]string{"say", "--msg", "--color=never"}, nil, ErrOptionsExpectsAValue{OptionName: "msg"}, 2},
+		{[]string{"say", "--color", "red"}, nil, ErrInvalidColorMode{Mode: "red"}, 3},
+		{[]string{"exec", "ls", "--color=never"}, []interface{}{"ls", []string{"--color=never"}}, nil, 0},
+		{[]string{"--color", "never", "--color=auto", "say", "--what"}, nil, ErrUnexpectedOptionOrFlag{OptionOrFlagName: "what"}, 5},
+		{[]string{"--color", "never"}, nil, ErrMissingSubcmd, -1},
+		{[]string{"mix", "--color", "never"}, []interface{}{false}, nil, 0},
+		{[]string{"mix", "--colo"}, []interface{}{true}, nil, 0},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			values = nil
+
+			app := &App{
+				Name:        "testing",
+				Root:        set,
+				IO:          IOStreams{Out: &bytes.Buffer{}},
+				ColorOption: true,
+			}
+
+			err := app.Run(test.strs)
+			if test.err != nil {
+				var errParse ErrParse
+				if !errors.As(err, &errParse) || errParse.Err != test.err {
+					t.Fatalf("got %v, want %v", err, test.err)
+				}
+
+				if errParse.Index != test.index {
+					t.Errorf("got %v, want %v", errParse.Index, test.index)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !reflect.DeepEqual(values, test.values) {
+				t.Errorf("got %v, want %v", values, test.values)
+			}
+		})
+	}
+}
+
+func TestColorOptionHelp(t *testing.T) {
+	set := NewSubcmdsSet(
+		Subcmd{
+			Name: "paint",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {},
+				Options: []CmdOption{
+					{Name: "color", T: TermString},
+				},
+			}),
+		},
+		Subcmd{
+			Name:   "say",
+			Parser: NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}}),
+		},
+	)
+
+	tests := []struct {
+		colorOption bool
+		strs        []string
+		listed      bool
+	}{
+		{true, []string{"--help"}, true},
+		{true, []string{"say", "--help"}, true},
+		{true, []string{"paint", "--help"}, false},
+		{false, []string{"say", "--help"}, false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:        "testing",
+				Root:        set,
+				IO:          IOStreams{Out: &out},
+				ColorOption: test.colorOption,
+			}
+
+			if err := app.Run(test.strs); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			listed := strings.Contains(out.String(), "Global options:\n  --color ")
+			if listed != test.listed {
+				t.Errorf("got %v, want %v in %q", listed, test.listed, out.String())
+			}
+		})
+	}
+}
+
+func TestColorOptionHelpAlignment(t *testing.T) {
+	tests := []struct {
+		root Parser
+		line string
+	}{
+		{
+			NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {},
+				Options: []CmdOption{
+					{Name: "verbosity", Alias: "v", T: TermInt},
+				},
+			}),
+			"  --color          whether",
+		},
+		{
+			NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}}),
+			"  --color  whether",
+		},
+		{
+			NewSubcmdsSet(Subcmd{
+				Name:   "housekeeping",
+				Parser: NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}}),
+			}),
+			"  --color       whether",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:              "testing",
+				Root:              test.root,
+				IO:                IOStreams{Out: &out},
+				ColorOption:       true,
+				DisableCompletion: true,
+				HelpWidth:         80,
+			}
+
+			if err := app.Run([]string{"--help"}); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !strings.Contains(out.String(), "\n"+test.line) {
+				t.Errorf("got %q, want it to contain %q", out.String(), test.line)
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ func newCompletionParser(rootCmdName string) Parser {
 			Description: "prints the bash completion",
 			Parser: NewCmd(CmdConfig{
 				Fn: func(terms *CmdTermsSet) {
-					fmt.Printf(completionStr, rootCmdName)
+					fmt.Fprintf(terms.Stdout(), completionStr, rootCmdName)
 				},
 			}),
 		},
@@ -30,7 +30,7 @@ func newCompletionParser(rootCmdName string) Parser {
 			Description: "prints the zsh completion",
 			Parser: NewCmd(CmdConfig{
 				Fn: func(terms *CmdTermsSet) {
-					fmt.Printf(completionStr, rootCmdName)
+					fmt.Fprintf(terms.Stdout(), completionStr, rootCmdName)
 				},
 			}),
 		},
@@ -2,7 +2,10 @@
 Package cfop provides a library for building CLIs.
 
 To start parsing a CLI, just call the Init function passing the name and description of the root command, the
-os.Args slice and a parser.
+os.Args slice and a parser. Init is a shorthand for creating an App and calling its Run method, which can be used
+directly for more control, e.g. over the streams used for input and output. An App isn't changed when run, so it can
+be run any number of times, including concurrently. Its Parse method resolves the terms to a ParseResult without
+running anything, leaving it up to the caller to run it.
 
 There are three parsers: rootCmd, Cmd and SubcmdsSet. The first one is just a reference to the root command's
 name and description (the data passed to the Init function) and is created implicitly by the same function.
@@ -73,6 +76,10 @@ next parser is foo's (SubcmdsSet). This parser, by the same means, will conclude
 Since bar's doesn't have any options, flags or aguments, it will only call the function provided to the Fn field,
 which will print hello world to the user.
 
+Instead of Fn, a Cmd can be given a CtxFn, which receives the context passed to InitContext and returns an error.
+This error is then returned by InitContext, so that failures inside a command can be handled by the caller, e.g.
+by Main, which prints it and exits with the appropriate code.
+
 This library also provides completion features. For more info, see the GitHub page of this package.
 */
 package cfop
@@ -3,6 +3,7 @@ package cfop
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // ErrInvalidOptionNameOrAlias indicates that an invalid option name or invalid option alias was provided.
@@ -14,9 +15,15 @@ var ErrInvalidFlagNameOrAlias = errors.New("cfop: invalid flag name or flag alia
 // ErrInvalidTermType indicates that an invalid term type was provided.
 var ErrInvalidTermType = errors.New("cfop: invalid term type")
 
+// ErrInvalidDuplicateKeyPolicy indicates that an invalid duplicate key policy was provided.
+var ErrInvalidDuplicateKeyPolicy = errors.New("cfop: invalid duplicate key policy")
+
 // ErrMissingRootCmdName indicates that a name for the root cmd wasn't provided.
 var ErrMissingRootCmdName = errors.New("cfop: missing name for root cmd")
 
+// ErrMissingRootParser indicates that a parser for the root cmd wasn't provided.
+var ErrMissingRootParser = errors.New("cfop: missing parser for root cmd")
+
 // ErrMissingSubcmdName indicates that a name for a subcmd wasn't provided.
 var ErrMissingSubcmdName = errors.New("cfop: missing name for subcmd")
 
@@ -26,6 +33,9 @@ var ErrMissingSubcmdParser = errors.New("cfop: missing parser for subcmd")
 // ErrMissingCmdFn indicates that a function for a cmd wasn't provided.
 var ErrMissingCmdFn = errors.New("cfop: missing function for cmd")
 
+// ErrMultipleCmdFns indicates that both Fn and CtxFn were provided for a cmd.
+var ErrMultipleCmdFns = errors.New("cfop: both Fn and CtxFn provided for cmd")
+
 // ErrMissingTermTypeForTerm indicates that a term's type wasn't provided.
 type ErrMissingTermTypeForTerm struct {
 	Term string
@@ -35,6 +45,16 @@ func (e ErrMissingTermTypeForTerm) Error() string {
 	return fmt.Sprintf("cfop: type not provided for term: %v", e.Term)
 }
 
+// ErrDuplicateKeysWithoutMap indicates that a duplicate key policy was
+// provided for an option that isn't a map option.
+type ErrDuplicateKeysWithoutMap struct {
+	Option string
+}
+
+func (e ErrDuplicateKeysWithoutMap) Error() string {
+	return fmt.Sprintf("cfop: duplicate key policy provided for option that isn't a map: %v", e.Option)
+}
+
 // ErrInvalidArgumentName indicates that an invalid argument name was provided.
 type ErrInvalidArgumentName struct {
 	ArgumentPos int
@@ -46,6 +66,89 @@ func (e ErrInvalidArgumentName) Error() string {
 
 // The errors below are those that are shown to the user.
 
+// ErrParse wraps an error that happened while parsing the terms, adding
+// where it happened. Every error shown to the user is wrapped by it, so
+// the underlying error can be checked with errors.Is and errors.As.
+type ErrParse struct {
+	// Cmds is the name of each cmd parsed until the error happened,
+	// starting with the root cmd's name.
+	Cmds []string
+	// Index is the position of Term in the terms, where 0 is the root
+	// cmd's name, e.g. the position in os.Args. If Term was read from a
+	// response file, it's the position of the term referencing the file.
+	// It's -1 if the error isn't related to a specific term, e.g. a
+	// missing argument.
+	Index int
+	// Term is the term that caused the error, if there's one.
+	Term string
+	// Usage is the usage line of the cmd being parsed, if there's one.
+	Usage string
+	Err   error
+}
+
+// newErrParse wraps err, which happened at strs[i], into an ErrParse.
+// strs are the terms received by the parser whose parent parser is pp
+// and usage is its usage line.
+// If i is out of the bounds of strs, the error isn't related to a term.
+func newErrParse(pp parentParser, usage string, strs []string, i int, err error) ErrParse {
+	e := ErrParse{
+		Cmds:  append([]string(nil), pp.cmds...),
+		Index: -1,
+		Usage: usage,
+		Err:   err,
+	}
+
+	if i >= 0 && i < len(strs) {
+		e.Index = len(pp.cmds) + pp.skipped + i
+		e.Term = strs[i]
+	}
+
+	return e
+}
+
+func (e ErrParse) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ErrParse) Unwrap() error {
+	return e.Err
+}
+
+// withOriginalIndexes maps the Index of err, if it's an ErrParse, or of
+// every error held by it, if it's an ErrParseAggregate, from the terms
+// parsed to the terms provided, where positions[i] is the position in
+// the terms provided of the term parsed at i, not counting the root
+// cmd's name. If positions is nil, err is returned as is.
+func withOriginalIndexes(err error, positions []int) error {
+	if positions == nil {
+		return err
+	}
+
+	withOriginalIndex := func(e ErrParse) ErrParse {
+		if i := e.Index - 1; i >= 0 && i < len(positions) {
+			e.Index = positions[i] + 1
+		}
+
+		return e
+	}
+
+	switch e := err.(type) {
+	case ErrParse:
+		return withOriginalIndex(e)
+	case ErrParseAggregate:
+		errs := make([]ErrParse, 0, len(e.Errs))
+
+		for _, errParse := range e.Errs {
+			errs = append(errs, withOriginalIndex(errParse))
+		}
+
+		return ErrParseAggregate{Errs: errs}
+	}
+
+	return err
+}
+
 // ErrUnexpectedOption indicates that an unexpected option or flag was provided.
 type ErrUnexpectedOption struct {
 	OptionName string
@@ -74,6 +177,21 @@ func (e ErrUnexpectedOptionOrFlag) Error() string {
 	return fmt.Sprintf("unexpected --%v option/flag", e.OptionOrFlagName)
 }
 
+// ErrAmbiguousOptionOrFlag indicates that an abbreviated option or flag matches more than one option or flag.
+type ErrAmbiguousOptionOrFlag struct {
+	OptionOrFlagName string
+	// Candidates are the names of the options and flags matched.
+	Candidates []string
+}
+
+func (e ErrAmbiguousOptionOrFlag) Error() string {
+	return fmt.Sprintf(
+		"ambiguous --%v option/flag, could be one of: --%v",
+		e.OptionOrFlagName,
+		strings.Join(e.Candidates, ", --"),
+	)
+}
+
 // ErrOptionExpectsDifferentValueType indicates that an option expects a value of a type different than the one provided.
 type ErrOptionExpectsDifferentValueType struct {
 	OptionName   string
@@ -117,6 +235,35 @@ func (e ErrOptionsExpectsAType) Error() string {
 	return fmt.Sprintf("--%v option expects a value", e.OptionName)
 }
 
+// ErrOptionExpectsKeyValuePair indicates that a map option expects a key/value pair, but one wasn't provided.
+type ErrOptionExpectsKeyValuePair struct {
+	OptionName string
+	IsAlias    bool
+}
+
+func (e ErrOptionExpectsKeyValuePair) Error() string {
+	if e.IsAlias {
+		return fmt.Sprintf("-%v option expects a value in the key=value format", e.OptionName)
+	}
+
+	return fmt.Sprintf("--%v option expects a value in the key=value format", e.OptionName)
+}
+
+// ErrDuplicateMapOptionKey indicates that a key was provided more than once to a map option that doesn't allow it.
+type ErrDuplicateMapOptionKey struct {
+	OptionName string
+	IsAlias    bool
+	Key        string
+}
+
+func (e ErrDuplicateMapOptionKey) Error() string {
+	if e.IsAlias {
+		return fmt.Sprintf("-%v option received the %v key more than once", e.OptionName, e.Key)
+	}
+
+	return fmt.Sprintf("--%v option received the %v key more than once", e.OptionName, e.Key)
+}
+
 // ErrUnexpectedArgument indicates that an unexpected argument was provided.
 type ErrUnexpectedArgument struct {
 	Argument string
@@ -138,8 +285,93 @@ func (e ErrArgumentExpectsDifferentValueType) Error() string {
 	return fmt.Sprintf("the <%v> argument (%v) expects a value of type %v", e.ArgumentName, e.Value, e.ExpectedType)
 }
 
+// ErrParseAggregate holds every error found while parsing a cmd whose
+// CmdConfig.CollectErrors is true.
+type ErrParseAggregate struct {
+	Errs []ErrParse
+}
+
+func (e ErrParseAggregate) Error() string {
+	return strings.Join(e.messages(), "\n")
+}
+
+// messages returns the message of each error held by e.
+func (e ErrParseAggregate) messages() []string {
+	msgs := make([]string, 0, len(e.Errs))
+
+	for _, err := range e.Errs {
+		// The usage line is left out, since it's the same for every
+		// error and it'd be in the middle of the message.
+		var errMissingArgs ErrMissingArguments
+		if errors.As(err.Err, &errMissingArgs) {
+			errMissingArgs.Usage = ""
+			msgs = append(msgs, errMissingArgs.Error())
+
+			continue
+		}
+
+		msgs = append(msgs, err.Error())
+	}
+
+	return msgs
+}
+
+// Is reports whether any error held by e matches target. It's used by
+// errors.Is, since Go versions before 1.20 don't follow multiple
+// wrapped errors.
+func (e ErrParseAggregate) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error held by e that matches target, and if one is
+// found, sets target to that error value and returns true. It's used by
+// errors.As, since Go versions before 1.20 don't follow multiple wrapped
+// errors.
+func (e ErrParseAggregate) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MissingArgument is an argument that wasn't provided.
+type MissingArgument struct {
+	Name string
+	// Pos is the position of the argument, starting at 0.
+	Pos int
+	T   TermType
+}
+
 // ErrMissingArguments indicates that not all arguments were provided.
-var ErrMissingArguments = errors.New("missing argument(s)")
+type ErrMissingArguments struct {
+	Args []MissingArgument
+	// Usage is the usage line of the cmd whose arguments are missing.
+	Usage string
+}
+
+func (e ErrMissingArguments) Error() string {
+	args := make([]string, 0, len(e.Args))
+
+	for _, arg := range e.Args {
+		args = append(args, fmt.Sprintf("#%v <%v> (%v)", arg.Pos+1, arg.Name, arg.T))
+	}
+
+	msg := fmt.Sprintf("missing argument(s): %v", strings.Join(args, ", "))
+	if e.Usage != "" {
+		msg += "\n" + e.Usage
+	}
+
+	return msg
+}
 
 // ErrRequiredOptionNotProvided indicates that a required option wasn't provided.
 type ErrRequiredOptionNotProvided struct {
@@ -150,6 +382,43 @@ func (e ErrRequiredOptionNotProvided) Error() string {
 	return fmt.Sprintf("--%v option is required", e.OptionName)
 }
 
+// ErrInvalidColorMode indicates that a color mode other than auto, always
+// or never was provided.
+type ErrInvalidColorMode struct {
+	Mode ColorMode
+}
+
+func (e ErrInvalidColorMode) Error() string {
+	return fmt.Sprintf("invalid color mode: %v (expected auto, always or never)", e.Mode)
+}
+
+// ErrInvalidHelpTemplate indicates that App.HelpTemplate isn't a valid
+// text/template.
+type ErrInvalidHelpTemplate struct {
+	Err error
+}
+
+func (e ErrInvalidHelpTemplate) Error() string {
+	return fmt.Sprintf("cfop: invalid help template: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e ErrInvalidHelpTemplate) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnknownSeeAlsoPath indicates that an entry of the SeeAlso of a cmd
+// isn't the path of a cmd or subcmds set of the App.
+type ErrUnknownSeeAlsoPath struct {
+	// Cmd is the path of the cmd whose SeeAlso has the entry.
+	Cmd  string
+	Path string
+}
+
+func (e ErrUnknownSeeAlsoPath) Error() string {
+	return fmt.Sprintf("cfop: the see also path %q of %v isn't a cmd", e.Path, e.Cmd)
+}
+
 // ErrMissingSubcmd indicates that a subcmd wasn't provided.
 var ErrMissingSubcmd = errors.New("missing subcmd")
 
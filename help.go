@@ -1,24 +1,16 @@
 package cfop
 
 import (
-	"io"
-	"os"
 	"regexp"
+	"text/template"
 
 	"github.com/efreitasn/customo"
 )
 
-// helpWriter is the writer used to print help messages.
-var helpWriter io.Writer = os.Stdout
-
 // helpIndentationNumSpaces is the number of spaces prefixed to some
 // lines in a help message.
 var helpIndentationNumSpaces = 2
 
-// helpIndentationSpaces is the space character repeated helpIndentationNumSpaces
-// times.
-var helpIndentationSpaces = "  "
-
 var helpFlagRegExp = regexp.MustCompile("^(?:--help|-h)$")
 
 // numberOfSpacesNameAndDescription is the number of spaces between a
@@ -26,14 +18,44 @@ var helpFlagRegExp = regexp.MustCompile("^(?:--help|-h)$")
 // flag help name or an argument help name.
 var numSpacesHelpNameAndDescription = 2
 
-// helper provides a help message to be printed to the user.
+// helper provides the data of a help message to be printed to the user.
 type helper interface {
-	help(pp parentParser) string
+	helpData(pp parentParser) HelpData
 }
 
-// printHelp writes the given helper's help message to helpWriter.
-func printHelp(h helper, pp parentParser) {
-	helpWriter.Write([]byte(h.help(pp)))
+// defaultHelpTemplate is DefaultHelpTemplate parsed.
+var defaultHelpTemplate = template.Must(parseHelpTemplate(DefaultHelpTemplate))
+
+// parseHelpTemplate parses text as a help template. The functions
+// available to it are replaced before it's executed, since they depend
+// on where the help message is written.
+func parseHelpTemplate(text string) (*template.Template, error) {
+	return template.New("help").Funcs(helpFuncs(0, false)).Parse(text)
+}
+
+// printHelp writes the given helper's help message, rendered by pp's help
+// template, to pp's output stream.
+func printHelp(h helper, pp parentParser) error {
+	out := pp.streams.withDefaults().Out
+	numCols := getTermNumCols(out, pp.helpWidth, pp.helpMaxWidth)
+	color := useColor(pp.colorMode, out)
+
+	tmpl := pp.helpTemplate
+	if tmpl == nil {
+		tmpl = defaultHelpTemplate
+	}
+
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+
+	data := h.helpData(pp)
+	if !color {
+		data.unstyle()
+	}
+
+	return tmpl.Funcs(helpFuncs(numCols, color)).Execute(out, data)
 }
 
 // buildOptionOrFlagHelpName buils an option/flag help name given
@@ -55,6 +77,22 @@ func buildOptionOrFlagHelpName(name, alias string) (styled, unstyled string) {
 	return styled, unstyled
 }
 
+// buildOptionHelpName builds an option help name like
+// buildOptionOrFlagHelpName, followed by key=value if it's a map option,
+// so that it's told how to provide its pairs.
+// For instance, for a map option whose name is label and alias is l,
+// the help name is: --label, -l key=value.
+func buildOptionHelpName(option *CmdOption) (styled, unstyled string) {
+	styled, unstyled = buildOptionOrFlagHelpName(option.Name, option.Alias)
+
+	if option.Map {
+		styled += " key=value"
+		unstyled += " key=value"
+	}
+
+	return styled, unstyled
+}
+
 // buildArgumentHelpName builds an argument help name given a name.
 // It expects to always receive a name != "".
 // It returns both a string with ANSI escape codes and one without
@@ -78,8 +116,8 @@ func findBiggestArgHelpNameLen(args map[string]*CmdArg) int {
 	for _, arg := range args {
 		_, helpName := buildArgumentHelpName(arg.Name)
 
-		if len(helpName) > biggest {
-			biggest = len(helpName)
+		if stringWidth(helpName) > biggest {
+			biggest = stringWidth(helpName)
 		}
 	}
 
@@ -90,18 +128,18 @@ func findBiggestOptionOrFlagHelpNameLen(options map[string]*CmdOption, flags map
 	biggest := 0
 
 	for _, option := range options {
-		_, helpName := buildOptionOrFlagHelpName(option.Name, option.Alias)
+		_, helpName := buildOptionHelpName(option)
 
-		if len(helpName) > biggest {
-			biggest = len(helpName)
+		if stringWidth(helpName) > biggest {
+			biggest = stringWidth(helpName)
 		}
 	}
 
 	for _, flag := range flags {
 		_, helpName := buildOptionOrFlagHelpName(flag.Name, flag.Alias)
 
-		if len(helpName) > biggest {
-			biggest = len(helpName)
+		if stringWidth(helpName) > biggest {
+			biggest = stringWidth(helpName)
 		}
 	}
 
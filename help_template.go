@@ -0,0 +1,139 @@
+package cfop
+
+import (
+	"strings"
+
+	"github.com/efreitasn/customo"
+)
+
+// DefaultHelpTemplate is the text/template used to render help messages
+// if App.HelpTemplate is empty. It's executed with a HelpData and,
+// besides the built-in functions, it can use:
+//
+//	bold STRING
+//		Returns STRING in bold, if the output is styled.
+//	wrap PAD TEXT
+//		Breaks every line of TEXT into lines that fit in the terminal,
+//		padded with PAD spaces.
+//	item INDENT NAMEWIDTH TERM
+//		Returns the name of the HelpTerm TERM indented with INDENT
+//		spaces, followed by its description aligned with the ones of
+//		other terms whose names are up to NAMEWIDTH long.
+//	join STRINGS SEP
+//		Joins STRINGS with SEP, e.g. the Path of a HelpData.
+const DefaultHelpTemplate = `{{if .Description}}{{.Description}}
+
+{{end}}{{if .LongDescription}}{{wrap 0 .LongDescription}}
+
+{{end}}{{.Usage}}
+{{range $section := .Sections}}
+{{if .Title}}{{.Title}}
+{{end}}{{range .Terms}}{{item $section.Indent $section.NameWidth .}}
+{{end}}{{end}}{{if .Footer}}
+{{wrap 0 .Footer}}
+{{end}}`
+
+// HelpData is the data a help message is rendered from.
+type HelpData struct {
+	// Path is the name of each cmd executed to reach the cmd whose help
+	// message is rendered, starting with the root cmd's name.
+	Path            []string
+	Description     string
+	LongDescription string
+	// Usage is the usage line, e.g. Usage: app foo <name> [FLAGS].
+	Usage   string
+	Args    []HelpTerm
+	Options []HelpTerm
+	Flags   []HelpTerm
+	Subcmds []HelpTerm
+	// Sections are the lists of terms in the default help message, e.g.
+	// the [OPTIONS] is one or more of: list, including the ones of the
+	// groups, the examples and the see also list.
+	Sections []HelpSection
+	Examples []CmdExample
+	SeeAlso  []string
+	Footer   string
+}
+
+// HelpTerm is a term listed in a help message.
+type HelpTerm struct {
+	// Name is how the term is referenced, e.g. --year, -y for an option
+	// or <age> for an argument.
+	Name string
+	// StyledName is Name with ANSI escape codes, if the output is
+	// styled. Otherwise, it's the same as Name.
+	StyledName  string
+	Description string
+	Required    bool
+	Group       string
+}
+
+// HelpSection is a titled list of terms in a help message.
+type HelpSection struct {
+	Title string
+	Terms []HelpTerm
+	// Indent is the number of spaces before the name of each term.
+	Indent int
+	// NameWidth is the length of the biggest name of a term, so that
+	// the descriptions can be aligned.
+	NameWidth int
+}
+
+// unstyle removes the styling of every term in d.
+func (d *HelpData) unstyle() {
+	for _, terms := range [][]HelpTerm{d.Args, d.Options, d.Flags, d.Subcmds} {
+		for i := range terms {
+			terms[i].StyledName = terms[i].Name
+		}
+	}
+
+	for _, section := range d.Sections {
+		for i := range section.Terms {
+			section.Terms[i].StyledName = section.Terms[i].Name
+		}
+	}
+}
+
+// helpFuncs returns the functions available to help templates for a
+// terminal numCols wide. If color is false, nothing is styled.
+func helpFuncs(numCols int, color bool) map[string]interface{} {
+	return map[string]interface{}{
+		"bold": func(str string) string {
+			if !color {
+				return str
+			}
+
+			return customo.Format(str, customo.AttrBold)
+		},
+		"wrap": func(pad int, text string) string {
+			return breakStringIntoPaddedLines(pad, ' ', numCols, text)
+		},
+		"item": func(indent, nameWidth int, t HelpTerm) string {
+			return buildHelpItem(indent, nameWidth, numCols, t)
+		},
+		"join": strings.Join,
+	}
+}
+
+// buildHelpItem builds the line of t in a list of a help message, which
+// is its name indented with indent spaces followed by its description.
+// The description is aligned with the ones of the other terms, whose
+// names are up to nameWidth long.
+func buildHelpItem(indent, nameWidth, numCols int, t HelpTerm) string {
+	item := strings.Repeat(" ", indent) + t.StyledName
+
+	if t.Description != "" {
+		descripFormatted := breakStringIntoPaddedLines(
+			indent+numSpacesHelpNameAndDescription+nameWidth,
+			' ',
+			numCols,
+			t.Description,
+		)
+
+		// the new slice was created so that the help name could
+		// align with the description.
+		item += descripFormatted[stringWidth(t.Name)+indent:]
+	}
+
+	return item
+}
@@ -0,0 +1,128 @@
+package cfop
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/efreitasn/customo"
+)
+
+func TestAppHelpTemplate(t *testing.T) {
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "year", Alias: "y", T: TermInt, Required: true},
+		},
+		Flags: []CmdFlag{
+			{Name: "json", Group: "Output"},
+		},
+		Args: []CmdArg{
+			{Name: "name", T: TermString},
+		},
+	})
+	set := NewSubcmdsSet(Subcmd{Name: "foo", Description: "does foo", Parser: cmd})
+
+	tests := []struct {
+		tmpl string
+		strs []string
+		out  string
+		err  bool
+	}{
+		{
+			`{{join .Path " "}}|{{range .Args}}{{.Name}};{{end}}|{{range .Options}}{{.Name}} {{.Required}};{{end}}|{{range .Flags}}{{.Name}} {{.Group}};{{end}}`,
+			[]string{"foo", "--help"},
+			"testing foo|<name>;|--year, -y true;|--json Output;",
+			false,
+		},
+		{
+			`{{.Usage}}{{range .Subcmds}}|{{item 2 5 .}}{{end}}`,
+			[]string{"--help"},
+			"Usage: testing SUBCMD|  " + customo.Format("foo", customo.AttrBold) + "    does foo",
+			false,
+		},
+		{
+			`{{range .Sections}}{{.Title}}|{{end}}`,
+			[]string{"foo", "--help"},
+			"|OPTIONS is one or more of:|Output:|",
+			false,
+		},
+		{
+			`{{.Unknown}}`,
+			[]string{"--help"},
+			"",
+			true,
+		},
+		{
+			`{{if}}`,
+			[]string{"--help"},
+			"",
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:         "testing",
+				Root:         set,
+				IO:           IOStreams{Out: &out},
+				Color:        ColorAlways,
+				HelpTemplate: test.tmpl,
+				// The built-in subcmds would be listed too.
+				DisableCompletion: true,
+			}
+
+			err := app.Run(test.strs)
+			if (err != nil) != test.err {
+				t.Fatalf("got %v, want error %v", err, test.err)
+			}
+
+			if !test.err && out.String() != test.out {
+				t.Errorf("got %q, want %q", out.String(), test.out)
+			}
+		})
+	}
+}
+
+func TestAppInvalidHelpTemplate(t *testing.T) {
+	app := &App{
+		Name:         "testing",
+		Root:         NewSubcmdsSet(Subcmd{Name: "foo", Parser: NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}})}),
+		HelpTemplate: `{{if}}`,
+	}
+
+	_, err := app.Parse([]string{"foo"})
+
+	var errTemplate ErrInvalidHelpTemplate
+	if !errors.As(err, &errTemplate) {
+		t.Errorf("got %v, want ErrInvalidHelpTemplate", err)
+	}
+}
+
+func TestBuildHelpItem(t *testing.T) {
+	tests := []struct {
+		indent    int
+		nameWidth int
+		numCols   int
+		term      HelpTerm
+		res       string
+	}{
+		{2, 6, 80, HelpTerm{Name: "foo", StyledName: "*foo*"}, "  *foo*"},
+		{2, 6, 80, HelpTerm{Name: "foo", StyledName: "foo", Description: "bar"}, "  foo     bar"},
+		{0, 3, 10, HelpTerm{Name: "foo", StyledName: "foo", Description: "bar baz"}, "foo  bar\n     baz"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := buildHelpItem(test.indent, test.nameWidth, test.numCols, test.term)
+
+			if res != test.res {
+				t.Errorf("got %q, want %q", res, test.res)
+			}
+		})
+	}
+}
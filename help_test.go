@@ -1,7 +1,9 @@
 package cfop
 
 import (
+	"bytes"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/efreitasn/customo"
@@ -54,6 +56,39 @@ func TestBuildOptionOrFlagHelpName(t *testing.T) {
 		})
 	}
 }
+func TestBuildOptionHelpName(t *testing.T) {
+	tests := []struct {
+		option      CmdOption
+		resStyled   string
+		resUnstyled string
+	}{
+		{
+			CmdOption{Name: "first", Alias: "f"},
+			customo.Format("--first", customo.AttrBold) + ", " + customo.Format("-f", customo.AttrBold),
+			"--first, -f",
+		},
+		{
+			CmdOption{Name: "label", Alias: "l", Map: true},
+			customo.Format("--label", customo.AttrBold) + ", " + customo.Format("-l", customo.AttrBold) + " key=value",
+			"--label, -l key=value",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			resStyled, resUnstyled := buildOptionHelpName(&test.option)
+
+			if resStyled != test.resStyled {
+				t.Errorf("got %v, want %v", resStyled, test.resStyled)
+			}
+
+			if resUnstyled != test.resUnstyled {
+				t.Errorf("got %v, want %v", resUnstyled, test.resUnstyled)
+			}
+		})
+	}
+}
+
 func TestBuildArgumentHelpName(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -131,6 +166,22 @@ func TestFindBiggestOptionOrFlagHelpNameLen(t *testing.T) {
 			},
 			len("--lines, -l"),
 		},
+		{
+			map[string]*CmdOption{
+				"label": &CmdOption{
+					Name:  "label",
+					Alias: "l",
+					Map:   true,
+				},
+			},
+			map[string]*CmdFlag{
+				"lines": &CmdFlag{
+					Name:  "lines",
+					Alias: "l",
+				},
+			},
+			len("--label, -l key=value"),
+		},
 		{
 			map[string]*CmdOption{},
 			map[string]*CmdFlag{},
@@ -193,3 +244,382 @@ func TestFindBiggestArgHelpNameLen(t *testing.T) {
 		})
 	}
 }
+
+func TestHelpOrder(t *testing.T) {
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "zoom", T: TermInt, Required: true},
+			{Name: "apple", T: TermInt, Required: true},
+			{Name: "yellow", T: TermString},
+			{Name: "blue", T: TermString},
+		},
+		Flags: []CmdFlag{
+			{Name: "xray"},
+			{Name: "cyan"},
+		},
+	})
+	set := NewSubcmdsSet(
+		Subcmd{Name: "zeta", Parser: cmd},
+		Subcmd{Name: "alpha", Parser: cmd},
+	)
+	set.Add("mid", "", cmd)
+
+	tests := []struct {
+		alphabetical bool
+		strs         []string
+		order        []string
+	}{
+		{false, []string{"--help"}, []string{"zeta", "alpha", "mid"}},
+		{true, []string{"--help"}, []string{"alpha", "mid", "zeta"}},
+		{false, []string{"zeta", "--help"}, []string{"--zoom", "--apple", "--yellow", "--blue", "--xray", "--cyan"}},
+		{true, []string{"zeta", "--help"}, []string{"--apple", "--zoom", "--blue", "--yellow", "--cyan", "--xray"}},
+		{false, []string{"__introspect__", "zeta", ""}, []string{"--zoom", "--apple", "--yellow", "--blue", "--xray", "--cyan"}},
+		{true, []string{"__introspect__", ""}, []string{"alpha", "mid", "zeta"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:              "testing",
+				Root:              set,
+				IO:                IOStreams{Out: &out},
+				AlphabeticalOrder: test.alphabetical,
+			}
+
+			if err := app.Run(test.strs); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			last := -1
+
+			for _, name := range test.order {
+				i := strings.Index(out.String(), name)
+				if i <= last {
+					t.Fatalf("got %v out of order in %q, want %v", name, out.String(), test.order)
+				}
+
+				last = i
+			}
+		})
+	}
+}
+
+func TestHelpGroups(t *testing.T) {
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "format", T: TermString, Group: "Output options"},
+			{Name: "name", T: TermString},
+		},
+		Flags: []CmdFlag{
+			{Name: "debug", Group: "Debug flags"},
+			{Name: "json", Group: "Output options"},
+			{Name: "verbose"},
+		},
+		Groups: []string{"Debug flags"},
+	})
+	set := NewSubcmdsSet(
+		Subcmd{Name: "image", Parser: cmd, Group: "Management commands"},
+		Subcmd{Name: "run", Parser: cmd},
+	)
+
+	tests := []struct {
+		strs  []string
+		order []string
+	}{
+		{[]string{"--help"}, []string{"SUBCMD is one of:", "run", "Management commands:", "image"}},
+		{[]string{"run", "--help"}, []string{"[OPTIONS] is one or more of:", "--name", "[FLAGS] is one or more of:", "--verbose", "Debug flags:", "--debug", "Output options:", "--format", "--json"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{Name: "testing", Root: set, IO: IOStreams{Out: &out}}
+
+			if err := app.Run(test.strs); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			last := -1
+
+			for _, str := range test.order {
+				i := strings.Index(out.String(), str)
+				if i <= last {
+					t.Fatalf("got %v out of order in %q, want %v", str, out.String(), test.order)
+				}
+
+				last = i
+			}
+		})
+	}
+}
+
+func TestHelpSections(t *testing.T) {
+	var out bytes.Buffer
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Options: []CmdOption{
+			{Name: "name", T: TermString},
+		},
+		LongDescription: "Runs a cmd.\n\nIt's long.",
+		Examples: []CmdExample{
+			{Cmd: "testing run --name foo", Description: "runs foo"},
+			{Cmd: "testing run", Description: "runs"},
+		},
+		SeeAlso: []string{"testing ps"},
+		Footer:  "The footer.",
+	})
+	app := &App{
+		Name: "testing",
+		Root: NewSubcmdsSet(
+			Subcmd{Name: "run", Description: "runs", Parser: cmd},
+			Subcmd{Name: "ps", Parser: NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}})},
+		),
+		IO:    IOStreams{Out: &out},
+		Color: ColorAlways,
+	}
+
+	if err := app.Run([]string{"run", "--help"}); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	order := []string{
+		"runs\n\nRuns a cmd.\n\nIt's long.\n\nUsage: testing run [OPTIONS]\n",
+		"[OPTIONS] is one or more of:\n",
+		"Examples:\n",
+		customo.Format("testing run --name foo", customo.AttrBold) + "  runs foo\n",
+		customo.Format("testing run", customo.AttrBold) + "             runs\n",
+		"See also:\n  testing ps\n",
+		"\nThe footer.\n",
+	}
+	last := -1
+
+	for _, str := range order {
+		i := strings.Index(out.String(), str)
+		if i <= last {
+			t.Fatalf("got %q out of order in %q, want %q", str, out.String(), order)
+		}
+
+		last = i
+	}
+}
+
+func TestHelpWidth(t *testing.T) {
+	defer func(originalGetenv func(string) string) {
+		getenv = originalGetenv
+	}(getenv)
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {},
+		Flags: []CmdFlag{
+			{Name: "all", Description: "lists every entry, including hidden ones"},
+		},
+	})
+
+	tests := []struct {
+		width    int
+		maxWidth int
+		columns  string
+		flag     string
+	}{
+		{0, 0, "", "  --all  lists every entry, including hidden ones\n"},
+		{30, 0, "", "  --all  lists every entry,\n         including hidden ones\n"},
+		{0, 0, "30", "  --all  lists every entry,\n         including hidden ones\n"},
+		{0, 30, "", "  --all  lists every entry,\n         including hidden ones\n"},
+		{0, 30, "200", "  --all  lists every entry,\n         including hidden ones\n"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			getenv = func(key string) string {
+				if key == "COLUMNS" {
+					return test.columns
+				}
+
+				return ""
+			}
+
+			app := &App{
+				Name:         "testing",
+				Root:         cmd,
+				IO:           IOStreams{Out: &out},
+				HelpWidth:    test.width,
+				HelpMaxWidth: test.maxWidth,
+				// The built-in subcmds would be listed after the flags.
+				DisableCompletion: true,
+			}
+
+			if err := app.Run([]string{"--help"}); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !strings.HasSuffix(out.String(), test.flag) {
+				t.Errorf("got %q, want it to end with %q", out.String(), test.flag)
+			}
+		})
+	}
+}
+
+func TestBuiltinSubcmdsHelp(t *testing.T) {
+	fn := func(cts *CmdTermsSet) {}
+	builtins := "  completion  prints completion for a shell\n  version     prints the version\n"
+
+	tests := []struct {
+		root Parser
+		out  string
+	}{
+		{
+			NewSubcmdsSet(Subcmd{Name: "foo", Description: "does foo", Parser: NewCmd(CmdConfig{Fn: fn})}),
+			"Usage: testing SUBCMD\n\nSUBCMD is one of:\n  foo         does foo\n" + builtins,
+		},
+		{
+			NewSubcmdsSet(Subcmd{Name: "version", Description: "own version", Parser: NewCmd(CmdConfig{Fn: fn})}),
+			"Usage: testing SUBCMD\n\nSUBCMD is one of:\n  version     own version\n  completion  prints completion for a shell\n",
+		},
+		{
+			NewCmd(CmdConfig{Fn: fn}),
+			"Usage: testing\n\nBuilt-in subcmds:\n" + builtins,
+		},
+		{
+			NewCmd(CmdConfig{Fn: fn, Args: []CmdArg{{Name: "name", T: TermString}}}),
+			"Usage: testing <name>\n\n<name>\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name:    "testing",
+				Version: "1.2.3",
+				Root:    test.root,
+				IO:      IOStreams{Out: &out},
+			}
+
+			if err := app.Run([]string{"--help"}); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if out.String() != test.out {
+				t.Errorf("got %q, want %q", out.String(), test.out)
+			}
+		})
+	}
+}
+
+func TestHelpGroupsUsage(t *testing.T) {
+	fn := func(cts *CmdTermsSet) {}
+
+	tests := []struct {
+		root Parser
+		strs []string
+		out  string
+	}{
+		{
+			NewSubcmdsSet(Subcmd{
+				Name: "run",
+				Parser: NewCmd(CmdConfig{
+					Fn: fn,
+					Options: []CmdOption{
+						{Name: "token", T: TermString, Description: "the token", Required: true, Group: "Auth"},
+						{Name: "user", T: TermString, Required: true, Group: "Auth"},
+					},
+					Flags: []CmdFlag{
+						{Name: "verbose"},
+					},
+				}),
+			}),
+			[]string{"run", "--help"},
+			"Usage: testing run [FLAGS] AUTH\n\n[FLAGS] is one or more of:\n  --verbose\n\nAuth:\n  --token    the token (required)\n  --user     (required)\n",
+		},
+		{
+			NewSubcmdsSet(Subcmd{
+				Name: "run",
+				Parser: NewCmd(CmdConfig{
+					Fn: fn,
+					Options: []CmdOption{
+						{Name: "format", T: TermString, Group: "Output options"},
+					},
+					Flags: []CmdFlag{
+						{Name: "json", Group: "Output options"},
+					},
+				}),
+			}),
+			[]string{"run", "--help"},
+			"Usage: testing run [OUTPUT-OPTIONS]\n\nOutput options:\n  --format\n  --json\n",
+		},
+		{
+			NewSubcmdsSet(Subcmd{Name: "image", Parser: NewCmd(CmdConfig{Fn: fn}), Group: "Management commands"}),
+			[]string{"--help"},
+			"Usage: testing SUBCMD\n\nManagement commands:\n  image\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := &App{
+				Name: "testing",
+				Root: test.root,
+				IO:   IOStreams{Out: &out},
+				// The built-in subcmds would be listed without a group.
+				DisableCompletion: true,
+			}
+
+			if err := app.Run(test.strs); err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if out.String() != test.out {
+				t.Errorf("got %q, want %q", out.String(), test.out)
+			}
+		})
+	}
+}
+
+func TestValidateSeeAlso(t *testing.T) {
+	fn := func(cts *CmdTermsSet) {}
+	images := NewSubcmdsSet(
+		Subcmd{Name: "ls", Parser: NewCmd(CmdConfig{Fn: fn})},
+	)
+
+	tests := []struct {
+		seeAlso []string
+		err     error
+	}{
+		{[]string{"testing", "testing image", "testing image ls", "testing  run", "testing version"}, nil},
+		{[]string{"testing image lss"}, ErrUnknownSeeAlsoPath{Cmd: "testing run", Path: "testing image lss"}},
+		{[]string{"image ls"}, ErrUnknownSeeAlsoPath{Cmd: "testing run", Path: "image ls"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			app := &App{
+				Name:    "testing",
+				Version: "1.2.3",
+				Root: NewSubcmdsSet(
+					Subcmd{Name: "image", Parser: images},
+					Subcmd{Name: "run", Parser: NewCmd(CmdConfig{Fn: fn, SeeAlso: test.seeAlso})},
+				),
+			}
+
+			if err := app.ValidateSeeAlso(); err != test.err {
+				t.Errorf("got %v, want %v", err, test.err)
+			}
+
+			// Running isn't affected by an invalid see also path.
+			if _, err := app.Parse([]string{"image", "ls"}); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cfop
+
+import "context"
+
+// Handler is a function called with the terms set of the cmd reached,
+// like CmdConfig.CtxFn.
+type Handler func(context.Context, *CmdTermsSet) error
+
+// Middleware wraps the function of a cmd, e.g. to run code around it.
+type Middleware func(Handler) Handler
+
+// hooks holds the hooks and middlewares registered in a parser.
+type hooks struct {
+	before      []Handler
+	after       []Handler
+	middlewares []Middleware
+}
+
+// runHooks runs the function of a cmd, fn, with the hooks and
+// middlewares in chain, which are ordered from the root cmd to the cmd.
+// Before hooks are called from the root to the cmd, then the middlewares
+// wrapping fn, and, if no error is returned, after hooks are called from
+// the cmd to the root. The first error returned stops the execution.
+func runHooks(ctx context.Context, chain []*hooks, fn Handler, cts *CmdTermsSet) error {
+	for _, h := range chain {
+		for _, before := range h.before {
+			if err := before(ctx, cts); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for j := len(chain[i].middlewares) - 1; j >= 0; j-- {
+			fn = chain[i].middlewares[j](fn)
+		}
+	}
+
+	if err := fn(ctx, cts); err != nil {
+		return err
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, after := range chain[i].after {
+			if err := after(ctx, cts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
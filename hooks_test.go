@@ -0,0 +1,139 @@
+package cfop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		strs   []string
+		err    error
+		events []string
+	}{
+		{
+			[]string{"testing", "foo", "bar", "John"},
+			nil,
+			[]string{
+				"root before testing foo bar John",
+				"foo before",
+				"bar before",
+				"root mw1 start",
+				"root mw2 start",
+				"bar mw start",
+				"bar fn",
+				"bar mw end",
+				"root mw2 end",
+				"root mw1 end",
+				"bar after",
+				"foo after",
+				"root after",
+			},
+		},
+		{
+			[]string{"testing", "foo", "bar", "abort"},
+			errAbort,
+			[]string{
+				"root before testing foo bar abort",
+				"foo before",
+			},
+		},
+		{
+			[]string{"testing", "foo", "bar", "fail"},
+			errAbort,
+			[]string{
+				"root before testing foo bar fail",
+				"foo before",
+				"bar before",
+				"root mw1 start",
+				"root mw2 start",
+				"bar mw start",
+				"bar fn",
+				"bar mw end",
+				"root mw2 end",
+				"root mw1 end",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var events []string
+
+			hook := func(event string) Handler {
+				return func(ctx context.Context, cts *CmdTermsSet) error {
+					events = append(events, event)
+
+					return nil
+				}
+			}
+			mw := func(name string) Middleware {
+				return func(next Handler) Handler {
+					return func(ctx context.Context, cts *CmdTermsSet) error {
+						events = append(events, name+" start")
+						err := next(ctx, cts)
+						events = append(events, name+" end")
+
+						return err
+					}
+				}
+			}
+
+			cmd := NewCmd(CmdConfig{
+				CtxFn: func(ctx context.Context, cts *CmdTermsSet) error {
+					events = append(events, "bar fn")
+
+					if cts.GetArgString("name") == "fail" {
+						return errAbort
+					}
+
+					return nil
+				},
+				Args: []CmdArg{
+					{Name: "name", T: TermString},
+				},
+			})
+			cmd.Before(hook("bar before"))
+			cmd.After(hook("bar after"))
+			cmd.Use(mw("bar mw"))
+
+			fooSet := NewSubcmdsSet(Subcmd{Name: "bar", Parser: cmd})
+			fooSet.Before(
+				hook("foo before"),
+				func(ctx context.Context, cts *CmdTermsSet) error {
+					if cts.GetArgString("name") == "abort" {
+						return errAbort
+					}
+
+					return nil
+				},
+			)
+			fooSet.After(hook("foo after"))
+
+			rootSet := NewSubcmdsSet(Subcmd{Name: "foo", Parser: fooSet})
+			rootSet.Before(func(ctx context.Context, cts *CmdTermsSet) error {
+				events = append(events, fmt.Sprintf("root before %v %v", strings.Join(cts.Path(), " "), cts.GetArgString("name")))
+
+				return nil
+			})
+			rootSet.After(hook("root after"))
+			rootSet.Use(mw("root mw1"), mw("root mw2"))
+
+			err := Init("testing", "", test.strs, rootSet)
+			if err != test.err {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			if !reflect.DeepEqual(events, test.events) {
+				t.Errorf("got %q, want %q", events, test.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cfop
+
+import "sort"
+
+// orderedOptions returns the options of c in the order they were
+// declared or, if alphabetical is true, sorted by name.
+func (c *Cmd) orderedOptions(alphabetical bool) []*CmdOption {
+	options := append([]*CmdOption(nil), c.optionsByPos...)
+
+	if alphabetical {
+		sort.SliceStable(options, func(i, j int) bool {
+			return options[i].Name < options[j].Name
+		})
+	}
+
+	return options
+}
+
+// orderedFlags returns the flags of c in the order they were declared
+// or, if alphabetical is true, sorted by name.
+func (c *Cmd) orderedFlags(alphabetical bool) []*CmdFlag {
+	flags := append([]*CmdFlag(nil), c.flagsByPos...)
+
+	if alphabetical {
+		sort.SliceStable(flags, func(i, j int) bool {
+			return flags[i].Name < flags[j].Name
+		})
+	}
+
+	return flags
+}
+
+// orderedItems returns the subcmds of ss in the order they were added
+// or, if alphabetical is true, sorted by name.
+func (ss *SubcmdsSet) orderedItems(alphabetical bool) []*Subcmd {
+	items := make([]*Subcmd, 0, len(ss.order))
+
+	for _, name := range ss.order {
+		items = append(items, ss.items[name])
+	}
+
+	if alphabetical {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Name < items[j].Name
+		})
+	}
+
+	return items
+}
+
+// orderedGroups returns the groups of c's options and flags in the order
+// they're listed in the help message.
+func (c *Cmd) orderedGroups(alphabetical bool) []string {
+	names := make([]string, 0, len(c.options)+len(c.flags))
+
+	for _, opt := range c.orderedOptions(alphabetical) {
+		names = append(names, opt.Group)
+	}
+
+	for _, f := range c.orderedFlags(alphabetical) {
+		names = append(names, f.Group)
+	}
+
+	return orderGroups(c.groups, names)
+}
+
+// orderGroups returns the groups in names without repetition, ordered by
+// their position in declared. Groups not in declared come after the ones
+// in it, in the order they first appear in names. The empty group is left
+// out.
+func orderGroups(declared []string, names []string) []string {
+	used := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		used[name] = true
+	}
+
+	groups := make([]string, 0, len(used))
+	seen := make(map[string]bool, len(used))
+
+	for _, group := range append(declared[:len(declared):len(declared)], names...) {
+		if group != "" && used[group] && !seen[group] {
+			groups = append(groups, group)
+			seen[group] = true
+		}
+	}
+
+	return groups
+}
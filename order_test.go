@@ -0,0 +1,29 @@
+package cfop
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestOrderGroups(t *testing.T) {
+	tests := []struct {
+		declared []string
+		names    []string
+		groups   []string
+	}{
+		{nil, []string{"", "b", "a", "b", ""}, []string{"b", "a"}},
+		{[]string{"a", "c"}, []string{"b", "a", "", "b"}, []string{"a", "b"}},
+		{[]string{"c"}, nil, []string{}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			groups := orderGroups(test.declared, test.names)
+
+			if !reflect.DeepEqual(groups, test.groups) {
+				t.Errorf("got %v, want %v", groups, test.groups)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package cfop
+
+import "context"
+
+// ParseResult is what a command line resolves to, as returned by
+// App.Parse.
+type ParseResult struct {
+	// Path is the name of each cmd reached, starting with the root's
+	// name.
+	Path []string
+	// Cmd is the cmd reached. It's nil if no cmd is reached, e.g. when
+	// the help message of a subcmds set or the version is asked for.
+	Cmd *Cmd
+	// Terms is the terms set of Cmd. It's nil if Help is true.
+	Terms *CmdTermsSet
+	// Help indicates whether the help message of the last cmd in Path is
+	// asked for.
+	Help bool
+	// run does what the command line asks for.
+	run func(context.Context) error
+}
+
+// Run does what the command line asks for, e.g. calling the function of
+// Cmd with Terms, along with its hooks and middlewares, or printing a
+// help message. Any error returned by the function is returned.
+func (r *ParseResult) Run(ctx context.Context) error {
+	if r.run == nil {
+		return nil
+	}
+
+	return r.run(ctx)
+}
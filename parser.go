@@ -1,9 +1,9 @@
 package cfop
 
 import (
-	"fmt"
+	"context"
 	"sort"
-	"strings"
+	"text/template"
 )
 
 // parentParser is a reference to the previous parser.
@@ -11,6 +11,63 @@ type parentParser struct {
 	// cmds is a slice containing the name of each cmd executed thus far.
 	cmds   []string
 	parser Parser
+	// result is where what the parsing resolves to is stored in
+	// parse-only mode. If it's nil, it's run right away instead.
+	result *ParseResult
+	// hooks holds the hooks of each parser executed thus far.
+	hooks []*hooks
+	// alphabetical is App.AlphabeticalOrder.
+	alphabetical bool
+	// helpTemplate is App.HelpTemplate parsed or, if it's empty, the
+	// default help template. It's cloned before being executed.
+	helpTemplate *template.Template
+	// helpWidth is App.HelpWidth.
+	helpWidth int
+	// helpMaxWidth is App.HelpMaxWidth.
+	helpMaxWidth int
+	// colorOption is App.ColorOption.
+	colorOption bool
+	// colorMode is App.Color or, if it was provided, the mode of the
+	// built-in --color option.
+	colorMode ColorMode
+	// builtins are the built-in subcmds of the App, such as completion,
+	// which are only accepted by the root parser. They're nil for any
+	// other parser.
+	builtins *SubcmdsSet
+	// optionsEnded indicates whether -- was parsed, so that every
+	// remaining term is taken as a subcmd name or an argument.
+	optionsEnded bool
+	// skipped is the number of terms parsed thus far that aren't the
+	// name of a cmd, e.g. the built-in --color option before a subcmd.
+	skipped int
+	// streams are the streams used by the parsing. Any of them that's
+	// nil defaults to the respective standard stream.
+	streams IOStreams
+}
+
+// resolve stores r, which is what the parsing resolves to, and run,
+// which does what it asks for, in pp's result. If pp isn't in parse-only
+// mode, run is called right away instead.
+func (pp parentParser) resolve(r ParseResult, run func(context.Context) error) error {
+	if pp.result == nil {
+		return run(context.Background())
+	}
+
+	r.run = run
+	*pp.result = r
+
+	return nil
+}
+
+// builtin returns the built-in subcmd named name, if pp has one.
+func (pp parentParser) builtin(name string) (*Subcmd, bool) {
+	if pp.builtins == nil {
+		return nil, false
+	}
+
+	subcmd, ok := pp.builtins.items[name]
+
+	return subcmd, ok
 }
 
 // Parser parses a slice of strings.
@@ -45,7 +102,11 @@ func getParentParserDescription(pp parentParser) string {
 	return ""
 }
 
-func introspectParser(strs []string, p Parser) []string {
+// introspectParser returns the terms accepted after strs by p, which are
+// used by the completion scripts. Options, flags and subcmds are listed
+// in the order they were declared or, if alphabetical is true, every
+// term is sorted.
+func introspectParser(strs []string, p Parser, alphabetical bool) []string {
 	res := []string{"--help", "-h"}
 
 	for i := 0; i < len(strs); i++ {
@@ -71,14 +132,14 @@ func introspectParser(strs []string, p Parser) []string {
 
 	switch cmdOrSet := p.(type) {
 	case *Cmd:
-		for _, opt := range cmdOrSet.options {
+		for _, opt := range cmdOrSet.orderedOptions(false) {
 			res = append(res, "--"+opt.Name)
 
 			if opt.Alias != "" {
 				res = append(res, "-"+opt.Alias)
 			}
 		}
-		for _, flag := range cmdOrSet.flags {
+		for _, flag := range cmdOrSet.orderedFlags(false) {
 			res = append(res, "--"+flag.Name)
 
 			if flag.Alias != "" {
@@ -86,74 +147,73 @@ func introspectParser(strs []string, p Parser) []string {
 			}
 		}
 	case *SubcmdsSet:
-		for _, item := range cmdOrSet.items {
+		for _, item := range cmdOrSet.orderedItems(false) {
 			res = append(res, item.Name)
 		}
 	}
 
-	sort.Strings(res)
+	if alphabetical {
+		sort.Strings(res)
+	}
 
 	return res
 }
 
-// Init initiates the parsing of the CLI.
-// The first item in strs is ignored, so that
-// os.Args can be used as the strs' value, which
-// is generally the case.
-func Init(name, description string, strs []string, p Parser) error {
-	if name == "" {
-		return ErrMissingRootCmdName
+// InitOption is an option that changes how Init parses the CLI by
+// changing the App it creates.
+type InitOption func(*App)
+
+// WithResponseFiles makes Init replace every term of the form @path with
+// the terms read from the file at path before parsing them. See
+// App.ResponseFiles.
+func WithResponseFiles(maxDepth int) InitOption {
+	return func(a *App) {
+		a.ResponseFiles = true
+		a.ResponseFilesMaxDepth = maxDepth
 	}
+}
 
-	rp := &rootCmd{
-		name:        name,
-		description: description,
+// WithAlphabeticalOrder makes Init list options, flags and subcmds sorted
+// by name. See App.AlphabeticalOrder.
+func WithAlphabeticalOrder() InitOption {
+	return func(a *App) {
+		a.AlphabeticalOrder = true
 	}
+}
 
-	var newStrs []string
-
-	if len(strs) == 1 {
-		newStrs = make([]string, 0)
-	} else {
-		newStrs = strs[1:]
+// newApp creates the App used by Init.
+func newApp(name, description string, p Parser, opts []InitOption) *App {
+	a := &App{
+		Name:        name,
+		Description: description,
+		Root:        p,
 	}
 
-	// Introspection
-	if len(newStrs) > 0 && newStrs[0] == "__introspect__" {
-		fmt.Println(strings.Join(
-			introspectParser(newStrs[1:], p),
-			" ",
-		))
-
-		return nil
+	for _, opt := range opts {
+		opt(a)
 	}
 
-	pp := parentParser{
-		cmds:   []string{name},
-		parser: rp,
-	}
-	completionParser := newCompletionParser(name)
+	return a
+}
 
-	switch subcmdsSetOrCmd := p.(type) {
-	case *SubcmdsSet:
-		subcmdsSetOrCmd.Add(
-			"completion",
-			"prints completion for a shell",
-			completionParser,
-		)
-	case *Cmd:
-		if newStrs[0] == "completion" {
-			set := NewSubcmdsSet(
-				Subcmd{
-					Name:        "completion",
-					Description: "prints completion for a shell",
-					Parser:      completionParser,
-				},
-			)
-
-			return set.Parse(pp, newStrs)
-		}
+// Init initiates the parsing of the CLI.
+// The first item in strs is ignored, so that
+// os.Args can be used as the strs' value, which
+// is generally the case.
+// It's a shorthand for App.Run.
+func Init(name, description string, strs []string, p Parser, opts ...InitOption) error {
+	return InitContext(context.Background(), name, description, strs, p, opts...)
+}
+
+// InitContext is like Init, but ctx is passed to the CtxFn of the cmd
+// reached, so that it can be cancelled. Any error returned by the CtxFn
+// is returned by InitContext.
+// It's a shorthand for App.RunContext.
+func InitContext(ctx context.Context, name, description string, strs []string, p Parser, opts ...InitOption) error {
+	var args []string
+	if len(strs) > 1 {
+		args = strs[1:]
 	}
 
-	return p.Parse(pp, newStrs)
+	return newApp(name, description, p, opts).RunContext(ctx, args)
 }
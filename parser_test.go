@@ -1,6 +1,10 @@
 package cfop
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
 	"reflect"
 	"strconv"
 	"testing"
@@ -59,7 +63,7 @@ func TestInstrospectParser(t *testing.T) {
 				},
 			),
 			[]string{"foo", "--y"},
-			[]string{"--help", "--year", "-h", "-y"},
+			[]string{"--help", "-h", "--year", "-y"},
 		},
 		{
 			NewSubcmdsSet(
@@ -84,7 +88,7 @@ func TestInstrospectParser(t *testing.T) {
 				},
 			),
 			[]string{"foo"},
-			[]string{"--age", "--help", "--year", "-a", "-h", "-y"},
+			[]string{"--help", "-h", "--age", "-a", "--year", "-y"},
 		},
 		{
 			NewSubcmdsSet(
@@ -109,7 +113,7 @@ func TestInstrospectParser(t *testing.T) {
 				},
 			),
 			[]string{"foo", "--age", "1990"},
-			[]string{"--age", "--help", "--year", "-a", "-h", "-y"},
+			[]string{"--help", "-h", "--age", "-a", "--year", "-y"},
 		},
 		{
 			NewSubcmdsSet(
@@ -165,7 +169,7 @@ func TestInstrospectParser(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			res := introspectParser(test.strs, test.p)
+			res := introspectParser(test.strs, test.p, false)
 
 			if !reflect.DeepEqual(res, test.res) {
 				t.Errorf("got %v, want %v", res, test.res)
@@ -173,3 +177,126 @@ func TestInstrospectParser(t *testing.T) {
 		})
 	}
 }
+
+func TestInitWithResponseFiles(t *testing.T) {
+	f, err := ioutil.TempFile("", "cfop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString("--name 'John Doe'\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var name, arg string
+
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {
+			name = cts.GetOptString("name")
+			arg = cts.GetArgString("first")
+		},
+		Options: []CmdOption{
+			{Name: "name", T: TermString},
+		},
+		Args: []CmdArg{
+			{Name: "first", T: TermString},
+		},
+	})
+
+	err = Init("testing", "", []string{"testing", "@" + f.Name(), "foo"}, cmd, WithResponseFiles(0))
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	if name != "John Doe" || arg != "foo" {
+		t.Errorf("got (%v, %v), want (John Doe, foo)", name, arg)
+	}
+
+	err = Init("testing", "", []string{"testing", "@" + f.Name(), "foo"}, cmd)
+	if err == nil {
+		t.Error("got nil, want an error when response files aren't enabled")
+	}
+
+	bad, err := ioutil.TempFile("", "cfop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bad.Name())
+
+	if _, err := bad.WriteString("--name x --age 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	bad.Close()
+
+	// The index of a term after a response file, or read from one, is
+	// the one in the terms provided.
+	tests := []struct {
+		strs  []string
+		term  string
+		index int
+	}{
+		{[]string{"testing", "@" + f.Name(), "foo", "bar"}, "bar", 3},
+		{[]string{"testing", "foo", "@" + f.Name(), "--name="}, "--name=", 3},
+		{[]string{"testing", "foo", "@" + bad.Name()}, "--age", 2},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := Init("testing", "", test.strs, cmd, WithResponseFiles(0))
+
+			var errParse ErrParse
+			if !errors.As(err, &errParse) {
+				t.Fatalf("got %v, want ErrParse", err)
+			}
+
+			if errParse.Term != test.term || errParse.Index != test.index {
+				t.Errorf("got (%v, %v), want (%v, %v)", errParse.Term, errParse.Index, test.term, test.index)
+			}
+		})
+	}
+}
+
+func TestInitContext(t *testing.T) {
+	type ctxKey struct{}
+
+	errFn := errors.New("fn failed")
+
+	cmd := NewCmd(CmdConfig{
+		CtxFn: func(ctx context.Context, cts *CmdTermsSet) error {
+			if ctx.Value(ctxKey{}) != "foo" {
+				return errors.New("context not passed")
+			}
+
+			if cts.GetFlag("fail") {
+				return errFn
+			}
+
+			return nil
+		},
+		Flags: []CmdFlag{
+			{Name: "fail"},
+		},
+	})
+	set := NewSubcmdsSet(Subcmd{Name: "foo", Parser: cmd})
+	ctx := context.WithValue(context.Background(), ctxKey{}, "foo")
+
+	tests := []struct {
+		strs []string
+		err  error
+	}{
+		{[]string{"testing", "foo"}, nil},
+		{[]string{"testing", "foo", "--fail"}, errFn},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := InitContext(ctx, "testing", "", test.strs, set)
+
+			if err != test.err {
+				t.Errorf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package cfop
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// DefaultResponseFilesMaxDepth is the max depth used when expanding
+// response files if one isn't provided.
+const DefaultResponseFilesMaxDepth = 10
+
+// ErrResponseFileCycle indicates that a response file references itself,
+// either directly or through other response files.
+var ErrResponseFileCycle = errors.New("response file references itself")
+
+// ErrResponseFileMaxDepth indicates that response files are nested deeper
+// than allowed.
+var ErrResponseFileMaxDepth = errors.New("response files nested too deeply")
+
+// ErrUnterminatedQuote indicates that a quote was opened but never closed.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// ErrResponseFile indicates that a response file couldn't be expanded.
+type ErrResponseFile struct {
+	Path string
+	// Line is the line of the file where the error happened. It's 0 if
+	// the error isn't related to a specific line, e.g. if the file
+	// couldn't be read.
+	Line int
+	Err  error
+}
+
+func (e ErrResponseFile) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("response file %v: %v", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("response file %v:%v: %v", e.Path, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e ErrResponseFile) Unwrap() error {
+	return e.Err
+}
+
+// isResponseFileTerm returns whether str references a response file, e.g. @args.txt.
+func isResponseFileTerm(str string) bool {
+	return len(str) > 1 && str[0] == '@'
+}
+
+// expandResponseFiles replaces every term of the form @path in strs with
+// the terms read from the file at path. Response files can reference
+// other response files up to maxDepth levels, where the ones referenced
+// directly in strs are at level 1.
+// It also returns the position in strs of the term each term returned
+// came from, which is the term referencing the response file for the
+// terms read from one.
+func expandResponseFiles(strs []string, maxDepth int) ([]string, []int, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultResponseFilesMaxDepth
+	}
+
+	res := make([]string, 0, len(strs))
+	positions := make([]int, 0, len(strs))
+	visiting := make(map[string]bool)
+
+	for i, str := range strs {
+		expanded, err := expandResponseFilesAt([]string{str}, 1, maxDepth, "", nil, visiting)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for range expanded {
+			positions = append(positions, i)
+		}
+
+		res = append(res, expanded...)
+	}
+
+	return res, positions, nil
+}
+
+// expandResponseFilesAt expands the response files referenced in strs,
+// which are at depth. parent is the file strs were read from and
+// parentLines holds the line of each term in it, both being empty if
+// strs weren't read from a file. visiting holds the absolute paths of
+// the files being expanded.
+func expandResponseFilesAt(
+	strs []string,
+	depth, maxDepth int,
+	parent string,
+	parentLines []int,
+	visiting map[string]bool,
+) ([]string, error) {
+	res := make([]string, 0, len(strs))
+
+	for i, str := range strs {
+		if !isResponseFileTerm(str) {
+			res = append(res, str)
+			continue
+		}
+
+		path := str[1:]
+
+		// A relative path in a response file is relative to the
+		// directory of the file.
+		if parent != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(parent), path)
+		}
+
+		// readErr returns the error for a file that couldn't be read,
+		// which also cites the file and line referencing it, if any.
+		readErr := func(err error) error {
+			err = ErrResponseFile{Path: path, Err: err}
+
+			if parent == "" {
+				return err
+			}
+
+			return ErrResponseFile{Path: parent, Line: parentLines[i], Err: err}
+		}
+
+		if depth > maxDepth {
+			return nil, ErrResponseFile{Path: parent, Line: parentLines[i], Err: ErrResponseFileMaxDepth}
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, readErr(err)
+		}
+
+		if visiting[absPath] {
+			return nil, ErrResponseFile{Path: parent, Line: parentLines[i], Err: ErrResponseFileCycle}
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, readErr(err)
+		}
+
+		fileStrs, fileLines, err := splitResponseFile(string(content))
+		if err != nil {
+			return nil, ErrResponseFile{Path: path, Line: fileLines[0], Err: err}
+		}
+
+		visiting[absPath] = true
+		expanded, err := expandResponseFilesAt(fileStrs, depth+1, maxDepth, path, fileLines, visiting)
+		delete(visiting, absPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, expanded...)
+	}
+
+	return res, nil
+}
+
+// splitResponseFile splits the content of a response file into terms.
+// Terms are separated by whitespace and can be quoted with ' or ". Inside
+// double quotes and outside quotes, \ escapes the next character. Lines
+// whose first non-whitespace character is # are ignored.
+// It returns the line at which each term starts. If an error is returned,
+// the only line returned is the one where the error happened.
+func splitResponseFile(content string) (strs []string, lines []int, err error) {
+	var (
+		sb        strings.Builder
+		inTerm    bool
+		inComment bool
+		escaped   bool
+		quote     rune
+		line      = 1
+		termLine  = 1
+		quoteLine = 1
+		lineStart = true
+	)
+
+	for _, r := range content {
+		switch {
+		case inComment:
+			inComment = r != '\n'
+		case escaped:
+			escaped = false
+
+			// An escaped newline is a line continuation, so it's dropped.
+			if r != '\n' {
+				if !inTerm {
+					inTerm = true
+					termLine = line
+				}
+
+				sb.WriteRune(r)
+			}
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '#' && lineStart && !inTerm:
+			inComment = true
+		case unicode.IsSpace(r):
+			if inTerm {
+				strs = append(strs, sb.String())
+				lines = append(lines, termLine)
+				sb.Reset()
+				inTerm = false
+			}
+		case r == '\\':
+			escaped = true
+		default:
+			if !inTerm {
+				inTerm = true
+				termLine = line
+			}
+
+			if r == '\'' || r == '"' {
+				quote = r
+				quoteLine = line
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+
+		if r == '\n' {
+			line++
+		}
+
+		lineStart = (lineStart || r == '\n') && unicode.IsSpace(r) && quote == 0 && !inTerm
+	}
+
+	if quote != 0 {
+		return nil, []int{quoteLine}, ErrUnterminatedQuote
+	}
+
+	if inTerm {
+		strs = append(strs, sb.String())
+		lines = append(lines, termLine)
+	}
+
+	return strs, lines, nil
+}
@@ -0,0 +1,183 @@
+package cfop
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSplitResponseFile(t *testing.T) {
+	tests := []struct {
+		content string
+		strs    []string
+		lines   []int
+		err     error
+	}{
+		{
+			"--name John\n-l  foo\tbar\n",
+			[]string{"--name", "John", "-l", "foo", "bar"},
+			[]int{1, 1, 2, 2, 2},
+			nil,
+		},
+		{
+			"--name 'John Doe' \"it's \\\"here\\\"\"\n''",
+			[]string{"--name", "John Doe", "it's \"here\"", ""},
+			[]int{1, 1, 1, 2},
+			nil,
+		},
+		{
+			"# a comment\n  # another one\nfoo#bar \\\n baz a\\ b",
+			[]string{"foo#bar", "baz", "a b"},
+			[]int{3, 4, 4},
+			nil,
+		},
+		{
+			"foo\n'multi\nline'",
+			[]string{"foo", "multi\nline"},
+			[]int{1, 2},
+			nil,
+		},
+		{
+			"foo\nbar 'baz\n\n",
+			nil,
+			[]int{2},
+			ErrUnterminatedQuote,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			strs, lines, err := splitResponseFile(test.content)
+
+			if err != test.err {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			if !reflect.DeepEqual(strs, test.strs) {
+				t.Errorf("got %q, want %q", strs, test.strs)
+			}
+
+			if !reflect.DeepEqual(lines, test.lines) {
+				t.Errorf("got %v, want %v", lines, test.lines)
+			}
+		})
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"args.txt":   "--name 'John Doe'\n@" + filepath.Join(dir, "nested.txt") + " last",
+		"nested.txt": "-l\n--year=1990",
+		"cycle.txt":  "foo\n@" + filepath.Join(dir, "cycle.txt"),
+		"deep.txt":   "@" + filepath.Join(dir, "args.txt"),
+		"quote.txt":  "foo\n\"bar",
+		// Relative paths are relative to the file referencing them.
+		"relative.txt": "@nested.txt foo",
+		"broken.txt":   "foo\n@gone.txt",
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	tests := []struct {
+		strs     []string
+		maxDepth int
+		res      []string
+		// positions are the positions in strs of the terms of res.
+		positions []int
+		err       error
+	}{
+		{
+			[]string{"foo", "@" + path("args.txt"), "@", "bar"},
+			0,
+			[]string{"foo", "--name", "John Doe", "-l", "--year=1990", "last", "@", "bar"},
+			[]int{0, 1, 1, 1, 1, 1, 2, 3},
+			nil,
+		},
+		{
+			[]string{"@" + path("relative.txt"), "bar"},
+			0,
+			[]string{"-l", "--year=1990", "foo", "bar"},
+			[]int{0, 0, 0, 1},
+			nil,
+		},
+		{
+			[]string{"@" + path("cycle.txt")},
+			0,
+			nil,
+			nil,
+			ErrResponseFile{Path: path("cycle.txt"), Line: 2, Err: ErrResponseFileCycle},
+		},
+		{
+			[]string{"@" + path("deep.txt")},
+			2,
+			nil,
+			nil,
+			ErrResponseFile{Path: path("args.txt"), Line: 2, Err: ErrResponseFileMaxDepth},
+		},
+		{
+			[]string{"@" + path("quote.txt")},
+			0,
+			nil,
+			nil,
+			ErrResponseFile{Path: path("quote.txt"), Line: 2, Err: ErrUnterminatedQuote},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res, positions, err := expandResponseFiles(test.strs, test.maxDepth)
+
+			if err != test.err {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			if !reflect.DeepEqual(res, test.res) {
+				t.Errorf("got %q, want %q", res, test.res)
+			}
+
+			if !reflect.DeepEqual(positions, test.positions) {
+				t.Errorf("got %v, want %v", positions, test.positions)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, _, err := expandResponseFiles([]string{"@" + path("missing.txt")}, 0)
+
+		var rfErr ErrResponseFile
+		if !errors.As(err, &rfErr) || rfErr.Path != path("missing.txt") || !os.IsNotExist(rfErr.Err) {
+			t.Errorf("got %v, want a not exist error for %v", err, path("missing.txt"))
+		}
+	})
+
+	t.Run("missing nested file", func(t *testing.T) {
+		_, _, err := expandResponseFiles([]string{"@" + path("broken.txt")}, 0)
+
+		var rfErr, nestedErr ErrResponseFile
+		if !errors.As(err, &rfErr) || rfErr.Path != path("broken.txt") || rfErr.Line != 2 {
+			t.Fatalf("got %v, want an error for %v:2", err, path("broken.txt"))
+		}
+
+		if !errors.As(rfErr.Err, &nestedErr) || nestedErr.Path != path("gone.txt") || !os.IsNotExist(nestedErr.Err) {
+			t.Errorf("got %v, want a not exist error for %v", rfErr.Err, path("gone.txt"))
+		}
+	})
+}
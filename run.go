@@ -0,0 +1,102 @@
+package cfop
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exit codes used by Main.
+const (
+	// ExitCodeError is the exit code used when a cmd fails.
+	ExitCodeError = 1
+	// ExitCodeParseError is the exit code used when the terms are invalid.
+	ExitCodeParseError = 2
+)
+
+// ErrExit is an error carrying the exit code to be used by Main.
+type ErrExit struct {
+	Code int
+	// Err is the error printed to the user. If it's nil, nothing is
+	// printed.
+	Err error
+}
+
+func (e ErrExit) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %v", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ErrExit) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for err, which is:
+// 0 if err is nil; the code of an ErrExit, if err is or wraps one;
+// ExitCodeParseError if err is or wraps an ErrParse; and ExitCodeError
+// otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var errExit ErrExit
+	if errors.As(err, &errExit) {
+		return errExit.Code
+	}
+
+	var errParse ErrParse
+	if errors.As(err, &errParse) {
+		return ExitCodeParseError
+	}
+
+	return ExitCodeError
+}
+
+// Main calls Init with os.Args and exits. It's a shorthand for App.Main.
+func Main(name, description string, p Parser, opts ...InitOption) {
+	newApp(name, description, p, opts).Main()
+}
+
+// printError writes err to w in the format used by Main, where name is
+// the root cmd's name. If err is nil or an ErrExit without an error,
+// nothing is written.
+func printError(w io.Writer, name string, err error) {
+	var errExit ErrExit
+	if err == nil || (errors.As(err, &errExit) && errExit.Err == nil) {
+		return
+	}
+
+	sb := strings.Builder{}
+
+	// Every error of an aggregate is printed as if it were the only one.
+	var errAggregate ErrParseAggregate
+	isAggregate := errors.As(err, &errAggregate)
+
+	if isAggregate {
+		for _, msg := range errAggregate.messages() {
+			sb.WriteString(fmt.Sprintf("%v: %v\n", name, msg))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("%v: %v\n", name, err))
+	}
+
+	var errParse ErrParse
+	if errors.As(err, &errParse) {
+		// ErrMissingArguments already includes the usage line, unless
+		// it's aggregated, in which case it's printed once at the end.
+		var errMissingArgs ErrMissingArguments
+		if errParse.Usage != "" && (isAggregate || !errors.As(err, &errMissingArgs)) {
+			sb.WriteString(errParse.Usage + "\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("Run '%v --help' for more information.\n", strings.Join(errParse.Cmds, " ")))
+	}
+
+	w.Write([]byte(sb.String()))
+}
@@ -0,0 +1,103 @@
+package cfop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("foo"), ExitCodeError},
+		{ErrParse{Err: ErrMissingSubcmd}, ExitCodeParseError},
+		{fmt.Errorf("wrapped: %w", ErrParse{Err: ErrMissingSubcmd}), ExitCodeParseError},
+		{ErrExit{Code: 3, Err: errors.New("foo")}, 3},
+		{fmt.Errorf("wrapped: %w", ErrExit{Code: 4}), 4},
+		{ErrParseAggregate{Errs: []ErrParse{{Err: ErrMissingSubcmd}}}, ExitCodeParseError},
+		{fmt.Errorf("wrapped: %w", ErrParseAggregate{Errs: []ErrParse{{Err: ErrMissingSubcmd}}}), ExitCodeParseError},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			code := ExitCode(test.err)
+
+			if code != test.code {
+				t.Errorf("got %v, want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	tests := []struct {
+		err error
+		out string
+	}{
+		{nil, ""},
+		{ErrExit{Code: 3}, ""},
+		{ErrExit{Code: 3, Err: errors.New("foo")}, "testing: foo\n"},
+		{errors.New("bar"), "testing: bar\n"},
+		{
+			ErrParse{
+				Cmds:  []string{"testing", "foo"},
+				Usage: "Usage: testing foo <first>",
+				Err:   ErrUnexpectedArgument{Argument: "bar"},
+			},
+			"testing: unexpected argument: bar\n" +
+				"Usage: testing foo <first>\n" +
+				"Run 'testing foo --help' for more information.\n",
+		},
+		{
+			ErrParse{
+				Cmds:  []string{"testing", "foo"},
+				Usage: "Usage: testing foo <first>",
+				Err: ErrMissingArguments{
+					Args:  []MissingArgument{{Name: "first", T: TermInt}},
+					Usage: "Usage: testing foo <first>",
+				},
+			},
+			"testing: missing argument(s): #1 <first> (int)\n" +
+				"Usage: testing foo <first>\n" +
+				"Run 'testing foo --help' for more information.\n",
+		},
+		{
+			ErrParseAggregate{Errs: []ErrParse{
+				{
+					Cmds:  []string{"testing", "foo"},
+					Usage: "Usage: testing foo <first>",
+					Err:   ErrUnexpectedOptionOrFlag{OptionOrFlagName: "what"},
+				},
+				{
+					Cmds:  []string{"testing", "foo"},
+					Usage: "Usage: testing foo <first>",
+					Err: ErrMissingArguments{
+						Args:  []MissingArgument{{Name: "first", T: TermInt}},
+						Usage: "Usage: testing foo <first>",
+					},
+				},
+			}},
+			"testing: unexpected --what option/flag\n" +
+				"testing: missing argument(s): #1 <first> (int)\n" +
+				"Usage: testing foo <first>\n" +
+				"Run 'testing foo --help' for more information.\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			printError(&buf, "testing", test.err)
+
+			if buf.String() != test.out {
+				t.Errorf("got %q, want %q", buf.String(), test.out)
+			}
+		})
+	}
+}
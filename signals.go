@@ -0,0 +1,93 @@
+package cfop
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Exit codes used when the program is forced to exit after receiving a
+// signal, which are 128 plus the number of the signal, as in shells.
+const (
+	// ExitCodeInterrupted is used for SIGINT.
+	ExitCodeInterrupted = 128 + int(syscall.SIGINT)
+	// ExitCodeTerminated is used for SIGTERM.
+	ExitCodeTerminated = 128 + int(syscall.SIGTERM)
+)
+
+// exit is called to force the program to exit. It's a variable so that
+// it can be replaced when testing.
+var exit = os.Exit
+
+// WithSignals makes InitContext cancel the context passed to the CtxFn
+// of the cmd reached when SIGINT or SIGTERM is received. See App.Signals.
+func WithSignals(gracePeriod time.Duration) InitOption {
+	return func(a *App) {
+		a.Signals = true
+		a.SignalsGracePeriod = gracePeriod
+	}
+}
+
+// notifySignals returns a copy of parent that's cancelled when SIGINT
+// or SIGTERM is received. After that, if another one of them is received
+// or gracePeriod elapses, the program exits with the exit code of the
+// signal that forced it to, which is the first one if gracePeriod
+// elapses. stop must be called to stop watching the signals.
+func notifySignals(parent context.Context, gracePeriod time.Duration) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 2)
+	done := make(chan struct{})
+
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		var sig os.Signal
+
+		select {
+		case sig = <-sigs:
+			cancel()
+		case <-done:
+			return
+		}
+
+		var grace <-chan time.Time
+
+		if gracePeriod > 0 {
+			timer := time.NewTimer(gracePeriod)
+			defer timer.Stop()
+
+			grace = timer.C
+		}
+
+		select {
+		case sig = <-sigs:
+			exit(signalExitCode(sig))
+		case <-grace:
+			exit(signalExitCode(sig))
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+
+	return ctx, func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// signalExitCode returns the exit code used when the program is forced
+// to exit after receiving sig.
+func signalExitCode(sig os.Signal) int {
+	if sig == syscall.SIGTERM {
+		return ExitCodeTerminated
+	}
+
+	return ExitCodeInterrupted
+}
@@ -0,0 +1,95 @@
+package cfop
+
+import (
+	"context"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInitContextWithSignals(t *testing.T) {
+	tests := []struct {
+		// signals are the signals sent by the cmd.
+		signals     []syscall.Signal
+		gracePeriod time.Duration
+		// waitExit indicates whether the cmd waits for the program to
+		// exit instead of returning when its context is cancelled.
+		waitExit bool
+		exitCode int
+	}{
+		{[]syscall.Signal{syscall.SIGINT}, 0, false, -1},
+		{[]syscall.Signal{syscall.SIGINT, syscall.SIGINT}, 0, true, ExitCodeInterrupted},
+		{[]syscall.Signal{syscall.SIGINT, syscall.SIGTERM}, 0, true, ExitCodeTerminated},
+		{[]syscall.Signal{syscall.SIGTERM, syscall.SIGINT}, 0, true, ExitCodeInterrupted},
+		{[]syscall.Signal{syscall.SIGINT}, 50 * time.Millisecond, true, ExitCodeInterrupted},
+		{[]syscall.Signal{syscall.SIGTERM}, 50 * time.Millisecond, true, ExitCodeTerminated},
+	}
+
+	defer func(originalExit func(int)) {
+		exit = originalExit
+	}(exit)
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			exitCh := make(chan int, 1)
+			exit = func(code int) {
+				exitCh <- code
+			}
+
+			cmd := NewCmd(CmdConfig{
+				CtxFn: func(ctx context.Context, cts *CmdTermsSet) error {
+					if err := syscall.Kill(syscall.Getpid(), test.signals[0]); err != nil {
+						return err
+					}
+
+					select {
+					case <-ctx.Done():
+					case <-time.After(time.Second):
+						t.Fatal("timeout waiting for the context to be cancelled")
+					}
+
+					for _, sig := range test.signals[1:] {
+						if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+							return err
+						}
+					}
+
+					if test.waitExit {
+						select {
+						case code := <-exitCh:
+							exitCh <- code
+						case <-time.After(time.Second):
+							t.Fatal("timeout waiting for the program to exit")
+						}
+					}
+
+					return ctx.Err()
+				},
+			})
+
+			err := InitContext(
+				context.Background(),
+				"testing",
+				"",
+				[]string{"testing", "run"},
+				NewSubcmdsSet(Subcmd{Name: "run", Parser: cmd}),
+				WithSignals(test.gracePeriod),
+			)
+			if err != context.Canceled {
+				t.Errorf("got %v, want %v", err, context.Canceled)
+			}
+
+			exitCode := -1
+
+			select {
+			case exitCode = <-exitCh:
+			default:
+			}
+
+			if exitCode != test.exitCode {
+				t.Errorf("got %v, want %v", exitCode, test.exitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cfop
+
+import (
+	"io"
+	"os"
+)
+
+// IOStreams are the streams used by an invocation of the CLI. Any of
+// them that's nil defaults to the respective standard stream, e.g.
+// os.Stdout for Out.
+type IOStreams struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// WithIO makes Init use streams for every output it produces. See
+// App.IO.
+func WithIO(streams IOStreams) InitOption {
+	return func(a *App) {
+		a.IO = streams
+	}
+}
+
+// withDefaults returns a copy of s with every nil stream replaced with
+// the respective standard stream.
+func (s IOStreams) withDefaults() IOStreams {
+	if s.In == nil {
+		s.In = os.Stdin
+	}
+
+	if s.Out == nil {
+		s.Out = os.Stdout
+	}
+
+	if s.Err == nil {
+		s.Err = os.Stderr
+	}
+
+	return s
+}
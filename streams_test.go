@@ -0,0 +1,73 @@
+package cfop
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWithIO(t *testing.T) {
+	newSet := func() *SubcmdsSet {
+		return NewSubcmdsSet(
+			Subcmd{
+				Name:        "foo",
+				Description: "foo's description",
+				Parser: NewCmd(CmdConfig{
+					Fn: func(cts *CmdTermsSet) {
+						in, _ := ioutil.ReadAll(cts.Stdin())
+
+						cts.Stdout().Write([]byte("out: " + string(in)))
+						cts.Stderr().Write([]byte("err: " + string(in)))
+					},
+				}),
+			},
+		)
+	}
+
+	tests := []struct {
+		strs      []string
+		in        string
+		outPrefix string
+		out       string
+		err       string
+	}{
+		{[]string{"testing", "foo"}, "bar", "", "out: bar", "err: bar"},
+		{[]string{"testing", "foo", "--help"}, "", "foo's description\n\nUsage: testing foo\n", "", ""},
+		{[]string{"testing", "--help"}, "", "Usage: testing SUBCMD\n", "", ""},
+		{[]string{"testing", "completion", "bash"}, "", "_testing()", "", ""},
+		{[]string{"testing", "__introspect__", ""}, "", "", "--help -h foo\n", ""},
+	}
+
+	for i, test := range tests {
+		test := test
+
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+
+			var out, errOut bytes.Buffer
+
+			err := Init("testing", "", test.strs, newSet(), WithIO(IOStreams{
+				In:  strings.NewReader(test.in),
+				Out: &out,
+				Err: &errOut,
+			}))
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if test.outPrefix != "" && !strings.HasPrefix(out.String(), test.outPrefix) {
+				t.Errorf("got %q, want it to start with %q", out.String(), test.outPrefix)
+			}
+
+			if test.outPrefix == "" && out.String() != test.out {
+				t.Errorf("got %q, want %q", out.String(), test.out)
+			}
+
+			if errOut.String() != test.err {
+				t.Errorf("got %q, want %q", errOut.String(), test.err)
+			}
+		})
+	}
+}
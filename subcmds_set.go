@@ -1,6 +1,7 @@
 package cfop
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,42 +13,51 @@ type Subcmd struct {
 	Name        string
 	Description string
 	Parser      Parser
+	// Group is the title of the section of the help message in which
+	// the subcmd is listed, e.g. Management commands. If it's empty, the
+	// subcmd is listed with the other subcmds.
+	Group string
 }
 
 // SubcmdsSet is a set of subcmds.
 type SubcmdsSet struct {
 	items map[string]*Subcmd
+	// order holds the name of each subcmd in the order they were added.
+	order []string
+	// groups is the order in which the groups are listed, as set by
+	// SetGroups.
+	groups []string
+	hooks  hooks
 }
 
 // NewSubcmdsSet creates a subcmds set.
 func NewSubcmdsSet(items ...Subcmd) *SubcmdsSet {
-	itemsMap := make(map[string]*Subcmd, len(items))
+	ss := &SubcmdsSet{items: make(map[string]*Subcmd, len(items))}
 
-	for i := range items {
-		item := items[i]
-
-		if item.Name == "" {
-			panic(ErrMissingSubcmdName)
-		}
-
-		if item.Parser == nil {
-			panic(ErrMissingSubcmdParser)
-		}
-
-		itemsMap[item.Name] = &item
+	for _, item := range items {
+		ss.add(item)
 	}
 
-	return &SubcmdsSet{items: itemsMap}
+	return ss
 }
 
 // Add adds a subcmd to the set.
 // If name == "" or parser == nil, it panics.
 func (ss *SubcmdsSet) Add(name, description string, parser Parser) {
-	if name == "" {
+	ss.add(Subcmd{
+		Name:        name,
+		Description: description,
+		Parser:      parser,
+	})
+}
+
+// add adds item to the set. A subcmd added again keeps its position.
+func (ss *SubcmdsSet) add(item Subcmd) {
+	if item.Name == "" {
 		panic(ErrMissingSubcmdName)
 	}
 
-	if parser == nil {
+	if item.Parser == nil {
 		panic(ErrMissingSubcmdParser)
 	}
 
@@ -55,103 +65,188 @@ func (ss *SubcmdsSet) Add(name, description string, parser Parser) {
 		ss.items = make(map[string]*Subcmd)
 	}
 
-	ss.items[name] = &Subcmd{
-		Name:        name,
-		Description: description,
-		Parser:      parser,
+	if _, ok := ss.items[item.Name]; !ok {
+		ss.order = append(ss.order, item.Name)
 	}
+
+	ss.items[item.Name] = &item
+}
+
+// SetGroups sets the order in which the groups of the subcmds are listed
+// in the help message. Groups not in it are listed after the ones in it,
+// in the order they first appear.
+func (ss *SubcmdsSet) SetGroups(groups ...string) {
+	ss.groups = groups
+}
+
+// Before registers hooks to be called before the function of any cmd
+// under ss. If one of them returns an error, the execution stops and the
+// error is returned.
+func (ss *SubcmdsSet) Before(hooks ...Handler) {
+	ss.hooks.before = append(ss.hooks.before, hooks...)
+}
+
+// After registers hooks to be called after the function of any cmd
+// under ss, as long as it doesn't return an error.
+func (ss *SubcmdsSet) After(hooks ...Handler) {
+	ss.hooks.after = append(ss.hooks.after, hooks...)
+}
+
+// Use registers middlewares wrapping the function of any cmd under ss.
+// The first middleware registered is the outermost one.
+func (ss *SubcmdsSet) Use(middlewares ...Middleware) {
+	ss.hooks.middlewares = append(ss.hooks.middlewares, middlewares...)
 }
 
 // Parse parses a slice of strings.
 func (ss *SubcmdsSet) Parse(pp parentParser, strs []string) error {
 	if len(strs) == 0 {
-		return ErrMissingSubcmd
+		return newErrParse(pp, ss.usage(pp), strs, -1, ErrMissingSubcmd)
 	}
 
 	str := strs[0]
 
-	if isHelpFlag(str) {
-		printHelp(ss, pp)
+	// -- ends the options, so that the next term is taken as the name
+	// of a subcmd and every term after it as an argument.
+	if str == "--" && !pp.optionsEnded {
+		pp.optionsEnded = true
+		pp.skipped++
 
-		return nil
+		return ss.Parse(pp, strs[1:])
 	}
 
-	if isOptionWithValue(str) {
+	if isHelpFlag(str) && !pp.optionsEnded {
+		mode, errI, err := helpColorMode(pp, ss, strs, 1)
+		if err != nil {
+			return newErrParse(pp, ss.usage(pp), strs, errI, err)
+		}
+
+		pp.colorMode = mode
+
+		return pp.resolve(ParseResult{
+			Path: append([]string(nil), pp.cmds...),
+			Help: true,
+		}, func(context.Context) error {
+			return printHelp(ss, pp)
+		})
+	}
+
+	if pp.isColorOption(str) {
+		mode, end, err := parseColorOption(strs, 0)
+		if err != nil {
+			return newErrParse(pp, ss.usage(pp), strs, end, err)
+		}
+
+		pp.colorMode = mode
+		pp.skipped += end + 1
+
+		return ss.Parse(pp, strs[end+1:])
+	}
+
+	if isOptionWithValue(str) && !pp.optionsEnded {
 		optName, isAlias := extractOptionName(str)
 
-		return ErrUnexpectedOption{
+		return newErrParse(pp, ss.usage(pp), strs, 0, ErrUnexpectedOption{
 			OptionName: optName,
 			IsAlias:    isAlias,
-		}
+		})
 	}
 
-	if isOptionWithoutValue(str) {
+	if isOptionWithoutValue(str) && !pp.optionsEnded {
 		optName, isAlias := extractOptionName(str)
 
-		return ErrUnexpectedOptionOrFlag{
+		return newErrParse(pp, ss.usage(pp), strs, 0, ErrUnexpectedOptionOrFlag{
 			OptionOrFlagName: optName,
 			IsAlias:          isAlias,
-		}
+		})
 	}
 
+	subcmdPP := pp
+	subcmdPP.parser = ss
+	subcmdPP.builtins = nil
+
 	subcmd, ok := ss.items[str]
-	if !ok {
-		return ErrUnknownSubcmd{SubcmdName: str}
+	if ok {
+		subcmdPP.hooks = append(pp.hooks[:len(pp.hooks):len(pp.hooks)], &ss.hooks)
+	} else if subcmd, ok = pp.builtin(str); !ok {
+		return newErrParse(pp, ss.usage(pp), strs, 0, ErrUnknownSubcmd{SubcmdName: str})
 	}
 
-	return subcmd.Parser.Parse(parentParser{
-		parser: ss,
-		cmds:   append(pp.cmds, subcmd.Name),
-	}, strs[1:])
-}
+	subcmdPP.cmds = append(pp.cmds[:len(pp.cmds):len(pp.cmds)], subcmd.Name)
 
-func (ss *SubcmdsSet) help(pp parentParser) string {
-	numCols, _ := getTermNumCols()
+	return subcmd.Parser.Parse(subcmdPP, strs[1:])
+}
 
-	sb := strings.Builder{}
+// usage returns the usage line of ss, e.g. Usage: app foo SUBCMD.
+func (ss *SubcmdsSet) usage(pp parentParser) string {
+	return fmt.Sprintf("Usage: %v SUBCMD", strings.Join(pp.cmds, " "))
+}
 
-	ppDescription := getParentParserDescription(pp)
-	if ppDescription != "" {
-		sb.WriteString(ppDescription + "\n\n")
+// helpData returns the data of ss's help message.
+func (ss *SubcmdsSet) helpData(pp parentParser) HelpData {
+	data := HelpData{
+		Path:        append([]string(nil), pp.cmds...),
+		Description: getParentParserDescription(pp),
+		Usage:       ss.usage(pp),
 	}
 
-	sb.WriteString(fmt.Sprintf("Usage: %v SUBCMD", strings.Join(pp.cmds, " ")))
-	sb.WriteString("\n\n")
-	sb.WriteString("SUBCMD is one of:\n")
+	items := ss.orderedItems(pp.alphabetical)
+
+	// The built-in subcmds are listed after the ones of ss, unless
+	// they're overridden by them.
+	if pp.builtins != nil {
+		for _, item := range pp.builtins.orderedItems(pp.alphabetical) {
+			if _, ok := ss.items[item.Name]; !ok {
+				items = append(items, item)
+			}
+		}
+	}
 
 	biggestNameLen := 0
+	groups := make([]string, 0, len(items))
 
-	for _, item := range ss.items {
-		if len(item.Name) > biggestNameLen {
-			biggestNameLen = len(item.Name)
+	for _, item := range items {
+		if stringWidth(item.Name) > biggestNameLen {
+			biggestNameLen = stringWidth(item.Name)
 		}
+
+		groups = append(groups, item.Group)
+		data.Subcmds = append(data.Subcmds, HelpTerm{
+			Name:        item.Name,
+			StyledName:  customo.Format(item.Name, customo.AttrBold),
+			Description: item.Description,
+			Group:       item.Group,
+		})
 	}
 
-	for _, item := range ss.items {
-		if item.Name == "completion" {
-			continue
+	// The subcmds without a group are listed first, in a section that's
+	// left out if every subcmd has a group.
+	for i, group := range append([]string{""}, orderGroups(ss.groups, groups)...) {
+		section := HelpSection{
+			Title:     group + ":",
+			Indent:    helpIndentationNumSpaces,
+			NameWidth: biggestNameLen,
 		}
 
-		nameBold := customo.Format(item.Name, customo.AttrBold)
-
-		sb.WriteString(helpIndentationSpaces + nameBold)
-		if item.Description != "" {
-			descripFormatted := breakStringIntoPaddedLines(
-				helpIndentationNumSpaces+
-					numSpacesHelpNameAndDescription+
-					biggestNameLen,
-				' ',
-				numCols,
-				item.Description,
-			)
-
-			// the new slice was created so that the help name could
-			// align with the description.
-			sb.Write([]byte(descripFormatted[len(item.Name)+helpIndentationNumSpaces:]))
+		if i == 0 {
+			section.Title = "SUBCMD is one of:"
 		}
 
-		sb.WriteRune('\n')
+		for _, t := range data.Subcmds {
+			if t.Group == group {
+				section.Terms = append(section.Terms, t)
+			}
+		}
+
+		if len(section.Terms) > 0 {
+			data.Sections = append(data.Sections, section)
+		}
+	}
+
+	if section, ok := colorOptionHelpSection(pp, ss, biggestNameLen); ok {
+		data.Options = append(data.Options, section.Terms...)
+		data.Sections = append(data.Sections, section)
 	}
 
-	return sb.String()
+	return data
 }
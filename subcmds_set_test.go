@@ -1,6 +1,7 @@
 package cfop
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
 	"testing"
@@ -72,7 +73,7 @@ func TestSubcmdsSet(t *testing.T) {
 				},
 				cmds: []string{"testing"},
 			}, test.strs)
-			if err != test.err {
+			if !errors.Is(err, test.err) {
 				t.Fatalf("got %v, want %v", err, test.err)
 			}
 
@@ -98,3 +99,68 @@ func TestSubcmdsSet(t *testing.T) {
 		})
 	}
 }
+
+func TestSubcmdsSetDoubleDash(t *testing.T) {
+	var args []string
+
+	set := NewSubcmdsSet(
+		Subcmd{
+			Name: "say",
+			Parser: NewCmd(CmdConfig{
+				Fn: func(cts *CmdTermsSet) {
+					args = append(args, cts.GetArgString("msg"))
+				},
+				Flags: []CmdFlag{
+					{Name: "loud", Alias: "l"},
+				},
+				Args: []CmdArg{
+					{Name: "msg", T: TermString},
+				},
+			}),
+		},
+	)
+
+	tests := []struct {
+		strs  []string
+		args  []string
+		err   error
+		index int
+	}{
+		{[]string{"--", "say", "-l"}, []string{"-l"}, nil, 0},
+		{[]string{"say", "--", "-l"}, []string{"-l"}, nil, 0},
+		{[]string{"--", "say", "--"}, []string{"--"}, nil, 0},
+		{[]string{"--", "--help"}, nil, ErrUnknownSubcmd{SubcmdName: "--help"}, 2},
+		{[]string{"--", "say", "-l", "foo"}, nil, ErrUnexpectedArgument{Argument: "foo"}, 4},
+		{[]string{"--"}, nil, ErrMissingSubcmd, -1},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			args = nil
+
+			app := &App{Name: "testing", Root: set}
+
+			err := app.Run(test.strs)
+			if test.err != nil {
+				var errParse ErrParse
+				if !errors.As(err, &errParse) || errParse.Err != test.err {
+					t.Fatalf("got %v, want %v", err, test.err)
+				}
+
+				if errParse.Index != test.index {
+					t.Errorf("got %v, want %v", errParse.Index, test.index)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got %v, want nil", err)
+			}
+
+			if !reflect.DeepEqual(args, test.args) {
+				t.Errorf("got %v, want %v", args, test.args)
+			}
+		})
+	}
+}
@@ -1,55 +1,164 @@
 package cfop
 
 import (
-	"math"
-	"os"
+	"io"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/sys/unix"
 )
 
-// getTermNumCols returns the number of columns of the terminal.
-// If an error occur while getting the number of columns, the default
-// value (67) is returned.
-func getTermNumCols() (int, error) {
-	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
-	if err != nil {
-		return 67, err
+// defaultTermNumCols is the number of columns used when it can't be
+// detected.
+const defaultTermNumCols = 67
+
+// getTermNumCols returns the number of columns of the terminal output
+// written to w is displayed in. If width > 0, it's returned as is.
+// Otherwise, it's read from the COLUMNS environment variable or, if it
+// isn't set, from the terminal w is, if it's one. If neither is
+// available, defaultTermNumCols is used. If maxWidth > 0, the number
+// detected is limited to it.
+func getTermNumCols(w io.Writer, width, maxWidth int) int {
+	if width > 0 {
+		return width
+	}
+
+	numCols := defaultTermNumCols
+
+	if n, err := strconv.Atoi(getenv("COLUMNS")); err == nil && n > 0 {
+		numCols = n
+	} else if f, ok := w.(interface{ Fd() uintptr }); ok {
+		ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+		if err == nil && ws.Col > 0 {
+			numCols = int(ws.Col)
+		}
 	}
 
-	return int(ws.Col), nil
+	if maxWidth > 0 && numCols > maxWidth {
+		return maxWidth
+	}
+
+	return numCols
 }
 
-// breakStringIntoPaddedLines breaks str into lines padded with pad padChar whose length <= maxCharsPerLine.
+// lineIndentationRegExp matches the indentation of a line, including a
+// bullet, e.g. "  - " or "1. ".
+var lineIndentationRegExp = regexp.MustCompile(`^\s*(?:(?:[-*•]|\d+[.)])\s+)?`)
+
+// wordRegExp matches a word along with the whitespace before it.
+var wordRegExp = regexp.MustCompile(`(\s*)(\S+)`)
+
+// breakStringIntoPaddedLines breaks str into lines padded with pad padChar
+// that take at most maxCharsPerLine columns. Lines are broken at
+// whitespace and only words that don't fit in a line by themselves are
+// broken, with a hyphen unless the break is next to a wide character,
+// such as a CJK one, which can be broken around anywhere. The whitespace
+// between words is kept, except where a line is broken, and tabs are
+// expanded. The line breaks in str are kept, and the lines an indented or
+// bulleted line is broken into are aligned with its text.
 func breakStringIntoPaddedLines(pad int, padChar rune, maxCharsPerLine int, str string) string {
-	if maxCharsPerLine <= pad {
-		return strings.Repeat(string(padChar), maxCharsPerLine)
+	padding := strings.Repeat(string(padChar), pad)
+	lines := strings.Split(str, "\n")
+	res := make([]string, 0, len(lines))
+
+	for i, line := range lines {
+		// The first line is always padded, so that something can be
+		// written over its padding, e.g. a help name.
+		if line == "" && i > 0 {
+			res = append(res, "")
+
+			continue
+		}
+
+		for _, l := range wrapLine(expandTabs(line), maxCharsPerLine-pad) {
+			res = append(res, padding+l)
+		}
 	}
 
-	if (pad + len(str)) <= maxCharsPerLine {
-		return strings.Repeat(string(padChar), pad) + str
+	return strings.Join(res, "\n")
+}
+
+// wrapLine breaks line into lines that take at most width columns. See
+// breakStringIntoPaddedLines.
+func wrapLine(line string, width int) []string {
+	if width <= 0 || stringWidth(line) <= width {
+		return []string{line}
 	}
 
-	strBs := []byte(str)
+	prefix := lineIndentationRegExp.FindString(line)
 
-	numStrCharsPerLine := maxCharsPerLine - pad
-	numStrParts := int(math.Ceil(float64(len(str)) / float64(numStrCharsPerLine)))
+	hangWidth := stringWidth(prefix)
+	if hangWidth >= width {
+		hangWidth = 0
+	}
 
-	strParts := make([]string, 0, numStrParts)
+	hang := strings.Repeat(" ", hangWidth)
 
-	for i := 0; i < numStrParts; i++ {
-		max := i*numStrCharsPerLine + numStrCharsPerLine
+	var lines []string
 
-		if max > len(strBs) {
-			max = len(strBs)
+	cur, curWidth := prefix, stringWidth(prefix)
+	// empty indicates whether there's no word in cur.
+	empty := true
+
+	for _, match := range wordRegExp.FindAllStringSubmatch(line[len(prefix):], -1) {
+		space, word := match[1], match[2]
+		spaceWidth, wordWidth := stringWidth(space), stringWidth(word)
+
+		// A word that doesn't fit is moved to the next line, unless it
+		// starts with a wide character that fits, around which it can
+		// be broken.
+		if !empty && curWidth+spaceWidth+wordWidth > width &&
+			!startsWithWideRune(word, width-curWidth-spaceWidth) {
+			lines = append(lines, cur)
+			cur, curWidth, empty = hang, hangWidth, true
+		}
+
+		if !empty {
+			cur += space
+			curWidth += spaceWidth
 		}
 
-		partBs := strBs[i*numStrCharsPerLine : max]
-		strParts = append(strParts, string(partBs))
+		for curWidth+wordWidth > width {
+			part, rest, ok := splitWord(word, width-curWidth)
+			if !ok {
+				break
+			}
+
+			lines = append(lines, cur+part)
+			cur, curWidth = hang, hangWidth
+			word, wordWidth = rest, stringWidth(rest)
+		}
+
+		cur += word
+		curWidth += wordWidth
+		empty = false
+	}
+
+	return append(lines, cur)
+}
+
+// splitWord splits word into a part that takes at most width columns,
+// hyphenated unless it's broken next to a wide character, and the rest.
+// If no part fits, false is returned.
+func splitWord(word string, width int) (string, string, bool) {
+	part, rest := splitStringAtWidth(word, width)
+	if stringWidth(part) > width {
+		return "", "", false
+	}
+
+	if isWideBreak(part, rest) {
+		return part, rest, true
+	}
+
+	if width <= 1 {
+		return "", "", false
 	}
 
-	joined := strings.Join(strParts, "\n"+strings.Repeat(string(padChar), pad))
+	part, rest = splitStringAtWidth(word, width-1)
+	if stringWidth(part) > width-1 {
+		return "", "", false
+	}
 
-	// remove newline at 0.
-	return strings.Repeat(string(padChar), pad) + joined
+	return part + "-", rest, true
 }
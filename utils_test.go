@@ -19,7 +19,126 @@ func TestBreakStringIntoPaddedLines(t *testing.T) {
 			20,
 			"foo bar foo bar",
 			' ',
-			strings.Repeat(" ", 10) + "foo bar fo\n" + strings.Repeat(" ", 10) + "o bar",
+			strings.Repeat(" ", 10) + "foo bar\n" + strings.Repeat(" ", 10) + "foo bar",
+		},
+		{
+			0,
+			10,
+			"foo bar",
+			' ',
+			"foo bar",
+		},
+		{
+			0,
+			10,
+			"foo bar foo bar\n\nfoo",
+			' ',
+			"foo bar\nfoo bar\n\nfoo",
+		},
+		{
+			2,
+			10,
+			"foo bar foo\n\nfoo",
+			' ',
+			"  foo bar\n  foo\n\n  foo",
+		},
+		{
+			0,
+			10,
+			"abcdefghijklmnopqrst uv",
+			' ',
+			"abcdefghi-\njklmnopqr-\nst uv",
+		},
+		{
+			0,
+			10,
+			"foo abcdefghijklmno",
+			' ',
+			"foo\nabcdefghi-\njklmno",
+		},
+		{
+			0,
+			12,
+			"- foo bar baz\n  1. qux quux corge",
+			' ',
+			"- foo bar\n  baz\n  1. qux\n     quux\n     corge",
+		},
+		{
+			0,
+			10,
+			"日本語の説明です",
+			' ',
+			"日本語の説\n明です",
+		},
+		{
+			0,
+			10,
+			"日本 語の 説明",
+			' ',
+			"日本 語の\n説明",
+		},
+		{
+			0,
+			9,
+			"👍👍 ok ok",
+			' ',
+			"👍👍 ok\nok",
+		},
+		{
+			0,
+			6,
+			"café café",
+			' ',
+			"café\ncafé",
+		},
+		{
+			10,
+			10,
+			"foo bar",
+			' ',
+			strings.Repeat(" ", 10) + "foo bar",
+		},
+		{
+			2,
+			10,
+			"\nfoo",
+			'-',
+			"--\n--foo",
+		},
+		{
+			0,
+			10,
+			"foo 日本語の説明",
+			' ',
+			"foo 日本語\nの説明",
+		},
+		{
+			0,
+			6,
+			"abc日本語",
+			' ',
+			"abc日\n本語",
+		},
+		{
+			0,
+			8,
+			"a  b  c  d  e  f",
+			' ',
+			"a  b  c\nd  e  f",
+		},
+		{
+			0,
+			20,
+			"a\tb\nab\tc",
+			' ',
+			"a       b\nab      c",
+		},
+		{
+			0,
+			12,
+			"foo\tbar baz",
+			' ',
+			"foo     bar\nbaz",
 		},
 	}
 
@@ -33,7 +152,46 @@ func TestBreakStringIntoPaddedLines(t *testing.T) {
 			)
 
 			if res != test.res {
-				t.Errorf("got %v, want %v", []byte(res), []byte(test.res))
+				t.Errorf("got %q, want %q", res, test.res)
+			}
+		})
+	}
+}
+
+func TestGetTermNumCols(t *testing.T) {
+	defer func(originalGetenv func(string) string) {
+		getenv = originalGetenv
+	}(getenv)
+
+	tests := []struct {
+		width    int
+		maxWidth int
+		columns  string
+		numCols  int
+	}{
+		{0, 0, "", defaultTermNumCols},
+		{0, 0, "120", 120},
+		{0, 100, "120", 100},
+		{0, 50, "", 50},
+		{90, 50, "120", 90},
+		{0, 0, "abc", defaultTermNumCols},
+		{0, 0, "-5", defaultTermNumCols},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			getenv = func(key string) string {
+				if key == "COLUMNS" {
+					return test.columns
+				}
+
+				return ""
+			}
+
+			numCols := getTermNumCols(&strings.Builder{}, test.width, test.maxWidth)
+
+			if numCols != test.numCols {
+				t.Errorf("got %v, want %v", numCols, test.numCols)
 			}
 		})
 	}
@@ -0,0 +1,107 @@
+package cfop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// buildInfo is the part of the binary's build info that's included in
+// the version info.
+type buildInfo struct {
+	Module    string
+	Revision  string
+	Modified  bool
+	GoVersion string
+}
+
+// readBuildInfo reads the build info of the binary. It's a variable so
+// that it can be replaced when testing.
+var readBuildInfo = readDebugBuildInfo
+
+// versionInfo is the information printed by the built-in version flag
+// and subcmd.
+type versionInfo struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Module    string `json:"module,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// newVersionInfo creates the version info of a. If a.VersionBuildInfo
+// is true, it's enriched with the build info of the binary, if there's
+// one.
+func newVersionInfo(a *App) versionInfo {
+	vi := versionInfo{
+		Name:    a.Name,
+		Version: a.Version,
+	}
+
+	if !a.VersionBuildInfo {
+		return vi
+	}
+
+	bi, ok := readBuildInfo()
+	if !ok {
+		return vi
+	}
+
+	vi.Module = bi.Module
+	vi.Revision = bi.Revision
+	vi.Modified = bi.Modified
+	vi.GoVersion = bi.GoVersion
+
+	return vi
+}
+
+// printVersion writes a's version info to w, as JSON if asJSON is true.
+func printVersion(w io.Writer, a *App, asJSON bool) error {
+	vi := newVersionInfo(a)
+
+	if asJSON {
+		return json.NewEncoder(w).Encode(vi)
+	}
+
+	fmt.Fprintf(w, "%v %v\n", vi.Name, vi.Version)
+
+	if vi.Module != "" {
+		fmt.Fprintf(w, "module: %v\n", vi.Module)
+	}
+
+	if vi.Revision != "" {
+		if vi.Modified {
+			fmt.Fprintf(w, "revision: %v (modified)\n", vi.Revision)
+		} else {
+			fmt.Fprintf(w, "revision: %v\n", vi.Revision)
+		}
+	}
+
+	if vi.GoVersion != "" {
+		fmt.Fprintf(w, "go: %v\n", vi.GoVersion)
+	}
+
+	return nil
+}
+
+// isVersionFlag returns whether str is the built-in version flag.
+func isVersionFlag(str string) bool {
+	return str == "--version" || str == "-V"
+}
+
+// newVersionParser creates the parser of the built-in version subcmd.
+func newVersionParser(a *App) Parser {
+	return NewCmd(CmdConfig{
+		CtxFn: func(ctx context.Context, cts *CmdTermsSet) error {
+			return printVersion(cts.Stdout(), a, cts.GetFlag("json"))
+		},
+		Flags: []CmdFlag{
+			{
+				Name:        "json",
+				Description: "prints the version as JSON",
+			},
+		},
+	})
+}
@@ -0,0 +1,118 @@
+package cfop
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestAppVersion(t *testing.T) {
+	defer func(originalReadBuildInfo func() (buildInfo, bool)) {
+		readBuildInfo = originalReadBuildInfo
+	}(readBuildInfo)
+
+	readBuildInfo = func() (buildInfo, bool) {
+		return buildInfo{
+			Module:    "example.com/testing",
+			Revision:  "abc123",
+			Modified:  true,
+			GoVersion: "go1.13",
+		}, true
+	}
+
+	set := NewSubcmdsSet(
+		Subcmd{
+			Name:   "foo",
+			Parser: NewCmd(CmdConfig{Fn: func(cts *CmdTermsSet) {}}),
+		},
+	)
+	cmd := NewCmd(CmdConfig{
+		Fn: func(cts *CmdTermsSet) {
+			cts.Stdout().Write([]byte("cmd"))
+		},
+		Flags: []CmdFlag{
+			{Name: "verbose", Alias: "V"},
+		},
+	})
+
+	tests := []struct {
+		app  App
+		args []string
+		out  string
+		err  error
+	}{
+		{
+			App{Name: "testing", Version: "1.2.3", Root: set},
+			[]string{"--version"},
+			"testing 1.2.3\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", Root: set},
+			[]string{"-V"},
+			"testing 1.2.3\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", Root: set},
+			[]string{"version"},
+			"testing 1.2.3\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", Root: set},
+			[]string{"version", "--json"},
+			`{"name":"testing","version":"1.2.3"}` + "\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", VersionBuildInfo: true, Root: set},
+			[]string{"version"},
+			"testing 1.2.3\nmodule: example.com/testing\nrevision: abc123 (modified)\ngo: go1.13\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", VersionBuildInfo: true, Root: set},
+			[]string{"version", "--json"},
+			`{"name":"testing","version":"1.2.3","module":"example.com/testing","revision":"abc123","modified":true,"goVersion":"go1.13"}` + "\n",
+			nil,
+		},
+		{
+			App{Name: "testing", Version: "1.2.3", Root: cmd},
+			[]string{"-V"},
+			"cmd",
+			nil,
+		},
+		{
+			App{Name: "testing", Root: set},
+			[]string{"version"},
+			"",
+			ErrUnknownSubcmd{SubcmdName: "version"},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var out bytes.Buffer
+
+			app := test.app
+			app.IO = IOStreams{Out: &out}
+
+			err := app.Run(test.args)
+
+			var errParse ErrParse
+			if errors.As(err, &errParse) {
+				err = errParse.Err
+			}
+
+			if err != test.err {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			if out.String() != test.out {
+				t.Errorf("got %q, want %q", out.String(), test.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cfop
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		str   string
+		width int
+	}{
+		{"foo", 3},
+		{"café", 4},
+		{"cafe\u0301", 4},
+		{"日本語", 6},
+		{"한국어", 6},
+		{"👍", 2},
+		{"ａｂ", 4},
+		{"a\u200bb", 2},
+		{"\t", 0},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			width := stringWidth(test.str)
+
+			if width != test.width {
+				t.Errorf("got %v, want %v", width, test.width)
+			}
+		})
+	}
+}
+
+func TestSplitStringAtWidth(t *testing.T) {
+	tests := []struct {
+		str    string
+		width  int
+		prefix string
+		rest   string
+	}{
+		{"foobar", 3, "foo", "bar"},
+		{"foo", 3, "foo", ""},
+		{"日本語", 3, "日", "本語"},
+		{"日本語", 1, "日", "本語"},
+		{"", 3, "", ""},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			prefix, rest := splitStringAtWidth(test.str, test.width)
+
+			if prefix != test.prefix || rest != test.rest {
+				t.Errorf("got %q, %q, want %q, %q", prefix, rest, test.prefix, test.rest)
+			}
+		})
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		line string
+		res  string
+	}{
+		{"foo", "foo"},
+		{"\tfoo", "        foo"},
+		{"foo\tbar", "foo     bar"},
+		{"foobarba\tz", "foobarba        z"},
+		{"日本\tfoo", "日本    foo"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			res := expandTabs(test.line)
+
+			if res != test.res {
+				t.Errorf("got %q, want %q", res, test.res)
+			}
+		})
+	}
+}